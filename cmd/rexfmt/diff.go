@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContext  = 3       // lines of context around each change
+	diffMaxCells = 1 << 24 // largest table of line matches worked out, beyond it the changed lines are simply replaced
+)
+
+// one line of a diff: ' ' for a line in both, '-' for a removed and '+' for an added line
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// returns a unified diff of the two texts, "" if they are the same
+func unifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i += 1
+			continue
+		}
+		// a hunk runs from before this change until the context after the last change in reach
+		stt := i - diffContext
+		if stt < 0 {
+			stt = 0
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end += 1
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next += 1
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end += diffContext
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = next
+		}
+		oldStt, newStt := 1, 1
+		for _, l := range lines[:stt] {
+			if l.op != '+' {
+				oldStt += 1
+			}
+			if l.op != '-' {
+				newStt += 1
+			}
+		}
+		oldLen, newLen := 0, 0
+		for _, l := range lines[stt:end] {
+			if l.op != '+' {
+				oldLen += 1
+			}
+			if l.op != '-' {
+				newLen += 1
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStt, oldLen), hunkRange(newStt, newLen))
+		for _, l := range lines[stt:end] {
+			out.WriteString(string(l.op) + l.text + "\n")
+		}
+		i = end
+	}
+	return out.String()
+}
+
+func hunkRange(stt, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", stt-1)
+	case 1:
+		return fmt.Sprint(stt)
+	}
+	return fmt.Sprintf("%d,%d", stt, n)
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, l := range lines {
+		if strings.HasSuffix(l, "\n") {
+			lines[i] = l[:len(l)-1]
+		} else {
+			lines[i] = l + "\n\\ No newline at end of file"
+		}
+	}
+	return lines
+}
+
+// matches the lines with a longest common subsequence, after removing any common head and tail
+func diffLines(a, b []string) []diffLine {
+	var head, tail []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		head = append(head, diffLine{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		tail = append([]diffLine{{' ', a[len(a)-1]}}, tail...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	lines := head
+	if (len(a)+1)*(len(b)+1) > diffMaxCells {
+		for _, l := range a {
+			lines = append(lines, diffLine{'-', l})
+		}
+		for _, l := range b {
+			lines = append(lines, diffLine{'+', l})
+		}
+		return append(lines, tail...)
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i += 1
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j += 1
+		}
+	}
+	return append(lines, tail...)
+}
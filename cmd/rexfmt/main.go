@@ -0,0 +1,175 @@
+/*
+rexfmt formats JSON files with a rex Style, in the manner of gofmt.
+
+Usage:
+
+	rexfmt [flags] [path ...]
+
+With no paths the JSON is read from stdin and written to stdout.  Directories are
+walked for .json files.  The flags are:
+
+	-s style	Built-in style name, or the name of a style file (see rex.LoadStyle):
+					packed		AutoPack everything that holds only simple values (default)
+					autopack	AutoPack within 80 columns
+					table		packed, with arrays of simple objects as aligned tables
+					width-N		Layout within N columns
+	-w			Write the result back to the file rather than to stdout
+	-l			List the files whose formatting differs from the style's
+	-d			Print a unified diff of the changes rather than the result
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jayacarlson/rex"
+)
+
+var (
+	styleName = flag.String("s", "packed", "built-in style (packed, autopack, table, width-N) or style file")
+	write     = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	list      = flag.Bool("l", false, "list files whose formatting differs from the style's")
+	doDiff    = flag.Bool("d", false, "display diffs instead of rewriting files")
+
+	exitCode = 0
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: rexfmt [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	style, err := loadStyle(*styleName)
+	if err != nil {
+		report(err)
+		os.Exit(exitCode)
+	}
+	if flag.NArg() == 0 {
+		if *write {
+			report(fmt.Errorf("cannot use -w with standard input"))
+		} else if err := processFile("<standard input>", os.Stdin, os.Stdout, style); err != nil {
+			report(err)
+		}
+		os.Exit(exitCode)
+	}
+	for _, path := range flag.Args() {
+		if err := processPath(path, style); err != nil {
+			report(err)
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func report(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	exitCode = 2
+}
+
+// returns the built-in style of the name, or the style read from the named file
+func loadStyle(name string) (rex.Style, error) {
+	switch {
+	case name == "packed":
+		return rex.PackedStyle, nil
+	case name == "autopack":
+		return rex.Style{Steps: []rex.CleanerFunc{func(src string) string {
+			return rex.AutoPack(src, rex.AutoPackOptions{MaxWidth: 80})
+		}}}, nil
+	case name == "table":
+		return rex.Style{Steps: append(rex.PackedStyle.Steps[:len(rex.PackedStyle.Steps):len(rex.PackedStyle.Steps)], rex.AlignTable(nil))}, nil
+	case strings.HasPrefix(name, "width-"):
+		if n, err := strconv.Atoi(name[len("width-"):]); err == nil && n > 0 {
+			return rex.LayoutStyle(n), nil
+		}
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return rex.Style{}, fmt.Errorf("unknown style %q: %v", name, err)
+	}
+	defer f.Close()
+	return rex.LoadStyle(f)
+}
+
+func processPath(path string, style rex.Style) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return processNamedFile(path, style)
+	}
+	return filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".json" {
+			err = processNamedFile(path, style)
+		}
+		if err != nil {
+			report(err)
+		}
+		return nil
+	})
+}
+
+func processNamedFile(path string, style rex.Style) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return processFile(path, f, os.Stdout, style)
+}
+
+// formats the JSON read from in, reporting it to out as the flags ask
+func processFile(name string, in io.Reader, out io.Writer, style rex.Style) error {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	res, err := format(src, style)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if !bytes.Equal(src, res) {
+		if *list {
+			fmt.Fprintln(out, name)
+		}
+		if *write {
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(name, res, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		if *doDiff {
+			io.WriteString(out, unifiedDiff(name+".orig", name, string(src), string(res)))
+		}
+	}
+	if !*list && !*write && !*doDiff {
+		_, err = out.Write(res)
+	}
+	return err
+}
+
+// lays the JSON out with json.Indent using the style's indent, then applies the style
+func format(src []byte, style rex.Style) ([]byte, error) {
+	indent := style.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(src), "", indent); err != nil {
+		return nil, err
+	}
+	return []byte(style.Apply(buf.String()) + "\n"), nil
+}
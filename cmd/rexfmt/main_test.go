@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/rex"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestFormat(t *testing.T) {
+	source := `{"name":"mesh","origin":{"x":0,"y":0,"z":0},"silences":[{"stt":1,"end":20},{"stt":300,"end":4000}]}`
+	for _, c := range []struct{ style, expected string }{
+		{"packed", `{
+  "name": "mesh",
+  "origin": { "x": 0, "y": 0, "z": 0 },
+  "silences": [
+    { "stt": 1, "end": 20 },
+    { "stt": 300, "end": 4000 }
+  ]
+}
+`},
+		{"table", `{
+  "name": "mesh",
+  "origin": { "x": 0, "y": 0, "z": 0 },
+  "silences": [
+    { "stt":   1, "end":   20 },
+    { "stt": 300, "end": 4000 }
+  ]
+}
+`},
+		{"width-36", `{
+  "name": "mesh",
+  "origin": {
+    "x": 0,
+    "y": 0,
+    "z": 0
+  },
+  "silences": [
+    { "stt": 1, "end": 20 },
+    { "stt": 300, "end": 4000 }
+  ]
+}
+`},
+	} {
+		style, err := loadStyle(c.style)
+		if err != nil {
+			tst.Failed(t, dbg.IAm()+" "+c.style, err.Error())
+			continue
+		}
+		res, err := format([]byte(source), style)
+		if text := string(res); err != nil || text != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.style, "Expected in green, genereted in red")
+			tst.AsGreen(c.expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.style)
+		}
+	}
+	if _, err := format([]byte(`{"a":`), rex.PackedStyle); err == nil {
+		tst.Failed(t, dbg.IAm()+" Error", "No error for bad JSON")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Error")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "{\n  \"a\": 1,\n  \"b\": [\n    1,\n    2\n  ],\n  \"c\": 3,\n  \"d\": 4,\n  \"e\": 5,\n  \"f\": 6,\n  \"g\": 7,\n  \"h\": 8,\n  \"i\": 9,\n  \"j\": 10,\n  \"k\": [\n    8\n  ]\n}\n"
+	after := "{\n  \"a\": 1,\n  \"b\": [ 1, 2 ],\n  \"c\": 3,\n  \"d\": 4,\n  \"e\": 5,\n  \"f\": 6,\n  \"g\": 7,\n  \"h\": 8,\n  \"i\": 9,\n  \"j\": 10,\n  \"k\": [ 8 ]\n}\n"
+	expected := `--- x.json.orig
++++ x.json
+@@ -1,9 +1,6 @@
+ {
+   "a": 1,
+-  "b": [
+-    1,
+-    2
+-  ],
++  "b": [ 1, 2 ],
+   "c": 3,
+   "d": 4,
+   "e": 5,
+@@ -12,7 +9,5 @@
+   "h": 8,
+   "i": 9,
+   "j": 10,
+-  "k": [
+-    8
+-  ]
++  "k": [ 8 ]
+ }
+`
+	if text := unifiedDiff("x.json.orig", "x.json", before, after); text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
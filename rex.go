@@ -1,7 +1,9 @@
 package rex
 
 import (
+	"fmt"
 	"regexp"
+	"regexp/syntax"
 	"strings"
 )
 
@@ -21,7 +23,7 @@ import (
 
 	PackLines: CleanerFunc
 		Utility function, mainly as a JSON cleanup function
-		Creates a RexFunc that uses RexPlace to do all the work
+		Loops over the lines with a regexp, so it is safe to use on very large texts
 		Returns a string with all \n replaced by a single ' '
 		Any leading spaces are also removed from the source lines, as in:
 			`   apple,
@@ -32,10 +34,22 @@ import (
 		More complex utility function, mainly as a JSON cleanup function
 		As PackLines, but with a maximum length check for the joined lines
 
+	EachFunc: TYPE
+		Utility functions that take the []string generated by a regexp and return the
+		finished text and the remaining text to be searched again by RexReplaceEach
+
 	RexReplace:
 		Used to recursivly process data for re-formatting using a RexFunc & regexp
 		to process the text. The RexFunc usually just calls itself for further
-		processing until the regexp fails.  See PackLines for an example.
+		processing until the regexp fails.
+
+	RexReplaceEach:
+		Loop based version of RexReplace for large texts, where the recursion of a RexFunc
+		would take a lot of stack and intermediate strings.  The EachFunc is called for
+		each match and returns the finished text and the remaining text, so the common:
+			return x[1] + " " + RexReplace(x[2], rx, rf)
+		becomes:
+			return x[1] + " ", x[2]
 
 	RexGather:
 		Used to gather up text that matches a regular expression using a GatherFunc & regexp
@@ -55,18 +69,18 @@ import (
 		-- gathered could then be searched for unique entries if needed
 
 	RexCleanup:
-		Used to recursivly process text for re-formatting using a RexFunc & regexp
-		to process the text. The RexFunc usually just calls itself for further
-		processing until the regexp fails.  RexCleanup expects the regexp to generate
+		Used to process text for re-formatting using a CleanerFunc & regexp to process
+		the text. Each match is handed to the CleanerFunc in a loop, until the regexp
+		fails on the remaining text.  RexCleanup expects the regexp to generate
 		the []string in the following format:
 			x[1] == all leading text -- not modified
 			x[2] == text given to the CleanerFunc
 			x[3] == trailing text, passed back into RexCleanup
 
 	RexJSONCleanup:
-		Used to recursivly process JSON for re-formatting using a RexFunc & regexp
-		to process the JSON. The RexFunc usually just calls itself for further JSON
-		processing until the regexp fails.  RexCleanupJSON expects the regexp to
+		Used to process JSON for re-formatting using a CleanerFunc & regexp to process
+		the JSON. Each match is handed to the CleanerFunc in a loop, until the regexp
+		fails on the remaining JSON.  RexCleanupJSON expects the regexp to
 		generate the []string in the following format:
 			x[1] == all leading text -- not modified
 			x[2] == text given to the CleanerFunc
@@ -109,10 +123,13 @@ import (
 			... "(?:NAME1|NAME2|NAME3...)": ...
 		You can modify the behavior of the regexp by including the \n in the different capture
 		expressions.
+		Ending the regexp with a capture of all the remaining text, as in ((?s).*), keeps the
+		loop based functions linear on large texts: the tail is not scanned for every match.
 */
 
 type CleanerFunc func(string) string
 type RexFunc func([]string, *regexp.Regexp, RexFunc) string
+type EachFunc func([]string) (string, string)
 type GatherFunc func([]string, *regexp.Regexp, GatherFunc)
 
 var (
@@ -127,30 +144,16 @@ var (
 	// used internally
 	lineRex = regexp.MustCompile("(.*\n)((?s).*)")   // x[1] == 1st line (including \n), x[2] == all the rest
 	packRex = regexp.MustCompile(" *(.*)\n((?s).*)") // x[1] == 1st line (leading spaces removed, no \n), x[2] == all the rest
+
+	packTailRex = newTailRex(packRex)
 )
 
 func PackLines(src string) string {
-	src = RexReplace(src, packRex, func(x []string, rx *regexp.Regexp, rf RexFunc) string {
-		return x[1] + " " + RexReplace(x[2], rx, rf)
-	})
-	return " " + src
+	return packLines(src)
 }
 
 func PackLinesMax(src string, max int) string {
-	cur, result := "", ""
-	for x := packRex.FindStringSubmatch(src); x != nil; x = packRex.FindStringSubmatch(x[2]) {
-		if cur != "" && len(cur)+len(x[1]) > max {
-			result += "\n" + cur
-			cur = ""
-		}
-		cur += x[1] + " "
-	} // last x[2] is most likely a bunch of spaces which are tossed
-	if result == "" {
-		result = " " + cur
-	} else {
-		result += "\n" + cur + "\n"
-	}
-	return result
+	return packLinesMax(src, max)
 }
 
 func RexReplace(src string, rx *regexp.Regexp, rf RexFunc) string {
@@ -160,6 +163,22 @@ func RexReplace(src string, rx *regexp.Regexp, rf RexFunc) string {
 	return src
 }
 
+func RexReplaceEach(src string, rx *regexp.Regexp, ef EachFunc) string {
+	var result strings.Builder
+	tr := newTailRex(rx)
+	for i := findSubmatchIndex(tr, src); i != nil; i = findSubmatchIndex(tr, src) {
+		x := make([]string, len(i)/2)
+		for n := range x {
+			x[n] = subMatch(src, i, n)
+		}
+		var done string
+		done, src = ef(x)
+		result.WriteString(done)
+	}
+	result.WriteString(src)
+	return result.String()
+}
+
 func RexGather(src string, rx *regexp.Regexp, gf GatherFunc) {
 	if x := rx.FindStringSubmatch(src); x != nil {
 		gf(x, rx, gf)
@@ -168,67 +187,263 @@ func RexGather(src string, rx *regexp.Regexp, gf GatherFunc) {
 
 // regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
 func RexCleanup(src string, rx *regexp.Regexp, cf CleanerFunc) string {
-	if x := rx.FindStringSubmatch(src); x != nil {
-		return x[1] + cf(x[2]) + RexCleanup(x[3], rx, cf)
-	}
-	return src
+	return rexCleanup(src, rx, cf)
 }
 
 // regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
 func RexJSONCleanup(src string, rx *regexp.Regexp, cf CleanerFunc) string {
-	if x := rx.FindStringSubmatch(src); x != nil {
-		lead, sub := RemoveJSONPadding(x[2])
-		return x[1] + AddJSONPadding(lead, cf(sub)) + RexJSONCleanup(x[3], rx, cf)
-	}
-	return src
+	return rexJSONCleanup(src, rx, cf, nil)
 }
 
 // regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
 func RexJSONCleanupPost(src string, rx *regexp.Regexp, cf CleanerFunc, post CleanerFunc) string {
-	if x := rx.FindStringSubmatch(src); x != nil {
-		lead, sub := RemoveJSONPadding(x[2])
-		return x[1] + post(AddJSONPadding(lead, cf(sub))) + RexJSONCleanupPost(x[3], rx, cf, post)
-	}
-	return src
+	return rexJSONCleanup(src, rx, cf, post)
 }
 
 func RemoveJSONPadding(src string) (string, string) {
-	result := ""
+	return removeJSONPadding(src)
+}
+
+func AddJSONPadding(lead, src string) string {
+	return addJSONPadding(lead, src)
+}
+
+// The functions doing the work for both the string and the []byte versions
+
+type text interface {
+	string | []byte
+}
+
+func packLines[T text](src T) T {
+	result := []byte{' '}
+	for x := findSubmatchIndex(packTailRex, src); x != nil; x = findSubmatchIndex(packTailRex, src) {
+		result = append(result, subMatch(src, x, 1)...)
+		result = append(result, ' ')
+		src = subMatch(src, x, 2)
+	}
+	return T(append(result, src...))
+}
+
+func packLinesMax[T text](src T, max int) T {
+	var result, cur []byte
+	for x := findSubmatchIndex(packTailRex, src); x != nil; x = findSubmatchIndex(packTailRex, src) {
+		line := subMatch(src, x, 1)
+		if len(cur) > 0 && len(cur)+len(line) > max {
+			result = append(append(result, '\n'), cur...)
+			cur = cur[:0]
+		}
+		cur = append(append(cur, line...), ' ')
+		src = subMatch(src, x, 2)
+	} // last src is most likely a bunch of spaces which are tossed
+	if len(result) == 0 {
+		return T(append([]byte{' '}, cur...))
+	}
+	return T(append(append(append(result, '\n'), cur...), '\n'))
+}
+
+func rexCleanup[T text](src T, rx *regexp.Regexp, cf func(T) T) T {
+	result, err := rexCleanupCore(src, rx, func(sub T) (T, error) { return cf(sub), nil })
+	if err != nil {
+		panic(err) // only missing groups, cf can not fail
+	}
+	return result
+}
+
+func rexJSONCleanup[T text](src T, rx *regexp.Regexp, cf func(T) T, post func(T) T) T {
+	return rexCleanup(src, rx, func(sub T) T {
+		lead, sub := removeJSONPadding(sub)
+		text := addJSONPadding(lead, cf(sub))
+		if post != nil {
+			text = post(text)
+		}
+		return text
+	})
+}
+
+// the cleanup loop used by all the cleanup functions: stops at the first error from cf,
+// returning src unchanged and the error wrapped in a CleanupError, or the error from
+// missingGroups for a matching regexp without x[3]
+func rexCleanupCore[T text](src T, rx *regexp.Regexp, cf func(T) (T, error)) (T, error) {
+	var result []byte
+	tr := newTailRex(rx)
+	rest, base := src, 0 // base is the offset of rest in src
+	x := findSubmatchIndex(tr, rest)
+	if x != nil {
+		if err := missingGroups(rx); err != nil {
+			return src, err
+		}
+	}
+	for ; x != nil; x = findSubmatchIndex(tr, rest) {
+		text, err := cf(subMatch(rest, x, 2))
+		if err != nil {
+			at := base
+			if x[4] >= 0 {
+				at += x[4]
+			}
+			return src, &CleanupError{Offset: at, Line: countNL(src[:at]) + 1, Err: err}
+		}
+		result = append(result, subMatch(rest, x, 1)...)
+		result = append(result, text...)
+		if x[6] < 0 {
+			return T(result), nil
+		}
+		rest, base = rest[x[6]:x[7]], base+x[6]
+	}
+	return T(append(result, rest...)), nil
+}
+
+// returns the number of '\n' in src
+func countNL[T text](src T) int {
+	n := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			n += 1
+		}
+	}
+	return n
+}
+
+func missingGroups(rx *regexp.Regexp) error {
+	if n := rx.NumSubexp(); n < 3 {
+		return fmt.Errorf("rex: regexp `%s` has %d capture groups, needs 3: lead, body and tail", rx, n)
+	}
+	return nil
+}
+
+// returns the text of group n from the FindSubmatchIndex result x, empty if unmatched
+func subMatch[T text](src T, x []int, n int) T {
+	if x[2*n] < 0 {
+		return src[:0]
+	}
+	if b, ok := any(src).([]byte); ok { // capped, so appending to it can not overwrite src
+		return T(b[x[2*n]:x[2*n+1]:x[2*n+1]])
+	}
+	return src[x[2*n]:x[2*n+1]]
+}
+
+// returns the index of the first '\n' in src, -1 if none
+func indexNL[T text](src T) int {
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeJSONPadding[T text](src T) (T, T) {
+	var result []byte
 	if len(src) == 0 || src[0] != ' ' {
-		return result, src
+		return src[:0], src
 	}
 	var l = 0
 	for l < len(src) && src[l] == ' ' {
 		l += 1
 	}
 	lead := src[:l]
-	for len(src) >= l {
-		if i := strings.Index(src, "\n"); i >= 0 {
-			result += src[l : i+1]
-			src = src[i+1:]
-		} else {
-			return lead, result + src[l:]
+	for {
+		n := 0 // lines indented less than the 1st, as in unbalanced text, lose only their indent
+		for n < l && n < len(src) && src[n] == ' ' {
+			n += 1
+		}
+		i := indexNL(src)
+		if i < 0 {
+			return lead, T(append(result, src[n:]...))
 		}
+		result = append(result, src[n:i+1]...)
+		src = src[i+1:]
 	}
-	return lead, result + src
 }
 
-func AddJSONPadding(lead, src string) string {
-	result := ""
+func addJSONPadding[T text](lead, src T) T {
+	var result []byte
 	// check for '\n' at beginning and skip it
-	if src != "" && src[0] == '\n' {
-		result = "\n"
+	if len(src) > 0 && src[0] == '\n' {
+		result = append(result, '\n')
 		src = src[1:]
 	}
 	for len(src) > 0 {
-		if i := strings.Index(src, "\n"); i >= 0 {
-			result += lead + src[:i+1]
+		if i := indexNL(src); i >= 0 {
+			result = append(append(result, lead...), src[:i+1]...)
 			src = src[i+1:]
-		} else if result == "" { // single line result -- assume packed line
+		} else if len(result) == 0 { // single line result -- assume packed line
 			return src
 		} else {
-			return result + lead + src // lead needed for multi-line packs
+			return T(append(append(result, lead...), src...)) // lead needed for multi-line packs
 		}
 	}
-	return result
+	return T(result)
+}
+
+// regexps ending with a capture of all the remaining text, as in ((?s).*), make every search
+// run to the end of the text -- these are searched without it, with the capture set afterward.
+// Every match from the leftmost start then runs to the end of the text, so rx.Longest()
+// can not change the match either.  Made once for each call of a loop based function, so
+// nothing is kept for regexps compiled for a single call.
+type tailRex struct {
+	rx   *regexp.Regexp
+	head *regexp.Regexp // rx without the trailing .*, nil if rx has no such ending
+	caps []int          // captures ending with the trailing .*
+}
+
+func findSubmatchIndex[T text](tr tailRex, src T) []int {
+	search := tr.rx
+	if tr.head != nil {
+		search = tr.head
+	}
+	var x []int
+	switch s := any(src).(type) {
+	case string:
+		x = search.FindStringSubmatchIndex(s)
+	case []byte:
+		x = search.FindSubmatchIndex(s)
+	}
+	if x != nil && tr.head != nil {
+		x[1] = len(src)
+		for _, c := range tr.caps {
+			x[2*c+1] = len(src)
+		}
+	}
+	return x
+}
+
+func newTailRex(rx *regexp.Regexp) tailRex {
+	re, err := syntax.Parse(rx.String(), syntax.Perl)
+	if err != nil {
+		return tailRex{rx: rx}
+	}
+	caps, ok := cutRestOfText(re)
+	if !ok {
+		return tailRex{rx: rx}
+	}
+	head, err := regexp.Compile(re.String())
+	if err != nil || head.NumSubexp() != rx.NumSubexp() {
+		return tailRex{rx: rx}
+	}
+	return tailRex{rx: rx, head: head, caps: caps}
+}
+
+// removes the trailing .* from re, returning the captures it was ending
+func cutRestOfText(re *syntax.Regexp) ([]int, bool) {
+	switch re.Op {
+	case syntax.OpCapture:
+		if isRestOfText(re.Sub[0]) {
+			re.Sub[0] = &syntax.Regexp{Op: syntax.OpEmptyMatch}
+			return []int{re.Cap}, true
+		}
+		caps, ok := cutRestOfText(re.Sub[0])
+		return append(caps, re.Cap), ok
+	case syntax.OpConcat:
+		if last := re.Sub[len(re.Sub)-1]; !isRestOfText(last) {
+			return cutRestOfText(last)
+		}
+		re.Sub = re.Sub[:len(re.Sub)-1]
+		return nil, true
+	}
+	return nil, false
+}
+
+// reports if re is a greedy .* that matches newlines
+func isRestOfText(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpStar && re.Flags&syntax.NonGreedy == 0 && re.Sub[0].Op == syntax.OpAnyChar
 }
@@ -0,0 +1,162 @@
+package rex
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Alignment of the values of object members.
+
+	AlignOptions: TYPE
+		Controls which members AlignValues lines up
+			Keys:			Only align members with these names, all members if empty
+			StringsOnly:	Only align members with string values
+			Runs:			Align each run of lines holding a single member on their own,
+							rather than all the members of an object together
+			MinWidth:		Values start at least this many columns after the start of their key
+
+	AlignValues:
+		Returns a CleanerFunc that pads the members in each object so their values start in
+		the same column, as in:
+			"artist":       "FooBar & Boo",
+			"artist-match": "FooBarBoo",
+			"save-artist":  "FooBar",
+		Only members on a single line that start the line (or follow the object's opening {
+		on the line) are aligned, any other members packed onto the same line simply move
+		along.  The text can be a whole document or the body of an object as handed to a
+		CleanerFunc.
+*/
+
+type AlignOptions struct {
+	Keys        []string
+	StringsOnly bool
+	Runs        bool
+	MinWidth    int
+}
+
+// a member of an object found by AlignValues
+type alignMember struct {
+	key   int // offset of the key's opening quote
+	colon int // offset of the ':'
+	value int // offset of the value
+	end   int // offset following the member
+}
+
+func AlignValues(opts AlignOptions) CleanerFunc {
+	return func(src string) string {
+		var groups [][]alignMember
+		groups = append(groups, opts.groups(src, 0, len(src))...)
+		for _, c := range ScanJSON(src) {
+			if c.Kind == '{' && c.Close > 0 {
+				groups = append(groups, opts.groups(src, c.Open+1, c.Close)...)
+			}
+		}
+		type edit struct {
+			stt, end int
+			pad      string
+		}
+		var edits []edit
+		for _, g := range groups {
+			target := 0
+			for _, m := range g {
+				if n := column(src, m.colon) + 2; n > target {
+					target = n
+				}
+				if n := column(src, m.key) + opts.MinWidth; n > target {
+					target = n
+				}
+			}
+			for _, m := range g {
+				edits = append(edits, edit{m.colon + 1, m.value, strings.Repeat(" ", target-column(src, m.colon)-1)})
+			}
+		}
+		sort.Slice(edits, func(i, j int) bool { return edits[i].stt < edits[j].stt })
+		var result strings.Builder
+		done := 0
+		for _, e := range edits {
+			if e.stt < done {
+				continue // from unbalanced text, overlapping an edit already made
+			}
+			result.WriteString(src[done:e.stt])
+			result.WriteString(e.pad)
+			done = e.end
+		}
+		result.WriteString(src[done:])
+		return result.String()
+	}
+}
+
+// returns the groups of members in src[stt:end] to be aligned together
+func (o AlignOptions) groups(src string, stt, end int) [][]alignMember {
+	var (
+		groups [][]alignMember
+		group  []alignMember
+		prev   = -1 // end of the previous member
+	)
+	flush := func() {
+		if len(group) > 0 {
+			groups = append(groups, group)
+			group = nil
+		}
+	}
+	entries := jsonEntries(src[stt:end])
+	for i, e := range entries {
+		m, ok := o.member(src, stt+e.at, e.text)
+		if ok && prev >= 0 && !strings.Contains(src[prev:m.key], "\n") {
+			ok = false // not the first member on the line
+		}
+		if ok && o.Runs {
+			// a run only holds lines with a single member
+			ok = i == len(entries)-1 || strings.Contains(src[m.end:stt+entries[i+1].at], "\n")
+			if ok && len(group) > 0 && strings.Count(src[group[len(group)-1].key:m.key], "\n") != 1 {
+				flush()
+			}
+		}
+		if ok {
+			group = append(group, m)
+		} else if o.Runs {
+			flush()
+		}
+		prev = stt + e.at + len(e.text)
+	}
+	flush()
+	return groups
+}
+
+// locates the parts of the member in text, found at offset at in src
+func (o AlignOptions) member(src string, at int, text string) (alignMember, bool) {
+	m := alignMember{key: at, end: at + len(text)}
+	if text[0] != '"' || strings.Contains(text, "\n") {
+		return m, false
+	}
+	k := skipJSONString(text, 0)
+	c := skipJSONSpace(text, k)
+	if c >= len(text) || text[c] != ':' {
+		return m, false
+	}
+	v := skipJSONSpace(text, c+1)
+	if v >= len(text) {
+		return m, false
+	}
+	if o.StringsOnly && text[v] != '"' {
+		return m, false
+	}
+	if len(o.Keys) > 0 {
+		key, found := jsonUnquote(text[:k]), false
+		for _, n := range o.Keys {
+			found = found || n == key
+		}
+		if !found {
+			return m, false
+		}
+	}
+	m.colon, m.value = at+c, at+v
+	return m, true
+}
+
+// returns the column of src[at] on its line
+func column(src string, at int) int {
+	return utf8.RuneCountInString(src[strings.LastIndex(src[:at], "\n")+1 : at])
+}
@@ -0,0 +1,109 @@
+package rex
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestAlignValuesOptions(t *testing.T) {
+	source := `{
+  "id": 12,
+  "name": "Foo",
+  "location": { "x": 0, "y": 0 },
+  "a": "a", "b": "b",
+  "description": "a bar",
+  "nested": {
+    "k": 1,
+    "kind": "text"
+  }
+}`
+	expected := `{
+  "id":          12,
+  "name":        "Foo",
+  "location":    { "x": 0, "y": 0 },
+  "a":           "a", "b": "b",
+  "description": "a bar",
+  "nested": {
+    "k":    1,
+    "kind": "text"
+  }
+}`
+	text := AlignValues(AlignOptions{})(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" All", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" All")
+	}
+
+	expected = `{
+  "id": 12,
+  "name":     "Foo",
+  "location": { "x": 0, "y": 0 },
+  "a": "a", "b": "b",
+  "description": "a bar",
+  "nested": {
+    "k":    1,
+    "kind": "text"
+  }
+}`
+	text = AlignValues(AlignOptions{Runs: true, Keys: []string{"name", "location", "description", "k", "kind"}})(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Runs", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Runs")
+	}
+
+	expected = `{
+  "id": 12,
+  "name":        "Foo",
+  "location": { "x": 0, "y": 0 },
+  "a":           "a", "b": "b",
+  "description": "a bar",
+  "nested": {
+    "k": 1,
+    "kind": "text"
+  }
+}`
+	text = AlignValues(AlignOptions{StringsOnly: true})(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" StringsOnly", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" StringsOnly")
+	}
+}
+
+func TestAlignValuesBadJSON(t *testing.T) {
+	// fragments and unbalanced brackets, anywhere in the text
+	align := AlignValues(AlignOptions{})
+	panicked := ""
+	for i := 0; i < len(complexSource) && panicked == ""; i += 2 {
+		for _, bad := range []string{`"`, `{`, `}`, `]`, ""} {
+			src := complexSource[:i] + bad + complexSource[i:]
+			if bad == "" {
+				src = complexSource[i:]
+			}
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked = fmt.Sprintf("%q at %d: %v", bad, i, r)
+					}
+				}()
+				align(src)
+			}()
+		}
+	}
+	if panicked != "" {
+		tst.Failed(t, dbg.IAm(), "Panicked on "+panicked)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
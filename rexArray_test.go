@@ -198,11 +198,8 @@ func TestRexJSONCleanupArrays_PackEverything(t *testing.T) {
           [ 1, 2, 3, 4, 5, 6, 7, 8, 9, 10 ]
   ] ] ] ]
 }`
-	// RexJSONCleanup(arrayText, UnnamedJSONObjectRex, packEverything) could be used, but packEverything does the same,
-	// but without the removing / adding of lead spacing -- but also then adds a leading '\n' which needs removal
-	text := packEverything(arrayText)
-	text = removeBlankLines(text[1:])
-	text = RexJSONCleanup(text, UnnamedJSONObjectRex, concatArrays)
+	text := AutoPack(arrayText, AutoPackOptions{})
+	text = CollapseNestedArrays(text, CollapseOptions{})
 	if text != expected {
 		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
 		tst.AsGreen(expected)
@@ -0,0 +1,134 @@
+package rex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Automatic packing of json.MarshalIndent style JSON.
+
+	AutoPackOptions: TYPE
+		Controls what AutoPack is allowed to pack
+			MaxWidth:				Longest line AutoPack will create, 0 for no limit
+			Pack:					Selector for the containers that may be packed, nil for all
+			FlattenScalarArrays:	Arrays of only simple values are always put on one line,
+									even if too wide or not selected by Pack
+
+	AutoPack:
+		Packs JSON without needing any regexps or CleanerFuncs:
+			Objects / arrays holding only simple values are put on a single line, as in:
+				"location": { "x": 0, "y": 0, "z": 0 },
+			Objects / arrays holding other multi-line objects / arrays are left open, with
+			each run of simple values joined onto a line, and each object / array placed on
+			its own line and packed in the same way.
+		When a MaxWidth is given, any object / array that does not fit on one line is left
+		open, with its simple values joined onto lines up to the MaxWidth.  Containers not
+		selected by Pack keep one value per line.  Empty objects / arrays become {} and [].
+		The indentation of the source is kept.
+*/
+
+type AutoPackOptions struct {
+	MaxWidth            int
+	Pack                Selector
+	FlattenScalarArrays bool
+}
+
+type autoPacker struct {
+	AutoPackOptions
+	src   string
+	boxes map[int]Container // scanned containers by offset of their { or [
+}
+
+func AutoPack(src string, opts AutoPackOptions) string {
+	p := autoPacker{AutoPackOptions: opts, src: src, boxes: map[int]Container{}}
+	for _, c := range ScanJSON(src) {
+		p.boxes[c.Open] = c
+	}
+	result := strings.Join(p.pack(0, len(src), true), "\n")
+	if strings.HasSuffix(src, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+// returns the packed lines for the entries in src[stt:end]
+func (p *autoPacker) pack(stt, end int, join bool) []string {
+	var lines, run []string
+	lead := ""
+	flush := func() {
+		if len(run) > 0 {
+			lines = append(lines, p.joinValues(lead, run, join)...)
+			run = nil
+		}
+	}
+	for _, e := range jsonEntries(p.src[stt:end]) {
+		at := stt + e.at
+		if e.open < 0 {
+			if len(run) == 0 {
+				lead = lineIndent(p.src, at)
+			}
+			run = append(run, e.text)
+			continue
+		}
+		flush()
+		lines = append(lines, p.packContainer(e, at)...)
+	}
+	flush()
+	return lines
+}
+
+func (p *autoPacker) packContainer(e jsonEntry, at int) []string {
+	lead := lineIndent(p.src, at)
+	head, tail := e.text[:e.open+1], e.text[e.shut:]
+	inside := e.text[e.open+1 : e.shut]
+	if strings.TrimSpace(inside) == "" {
+		return []string{lead + head + tail}
+	}
+	c := p.boxes[at+e.open]
+	packable := p.Pack == nil || p.Pack(c)
+	if entries := jsonEntries(inside); p.allSimple(entries) {
+		values := make([]string, len(entries))
+		for i, s := range entries {
+			values[i] = s.text
+		}
+		line := lead + head + " " + strings.Join(values, " ") + " " + tail
+		if (packable && p.fits(line)) || (p.FlattenScalarArrays && c.Kind == '[') {
+			return []string{line}
+		}
+	}
+	lines := []string{lead + head}
+	lines = append(lines, p.pack(at+e.open+1, at+e.shut, packable)...)
+	return append(lines, lineIndent(p.src, at+e.shut)+tail)
+}
+
+func (p *autoPacker) allSimple(entries []jsonEntry) bool {
+	for _, e := range entries {
+		if e.open >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *autoPacker) fits(line string) bool {
+	return p.MaxWidth <= 0 || utf8.RuneCountInString(line) <= p.MaxWidth
+}
+
+// places the simple values on lines, each value on its own line unless join is set
+func (p *autoPacker) joinValues(lead string, values []string, join bool) []string {
+	var lines []string
+	cur := ""
+	for _, v := range values {
+		if cur != "" && (!join || !p.fits(cur+" "+v)) {
+			lines = append(lines, cur)
+			cur = ""
+		}
+		if cur == "" {
+			cur = lead + v
+		} else {
+			cur += " " + v
+		}
+	}
+	return append(lines, cur)
+}
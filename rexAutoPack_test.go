@@ -0,0 +1,84 @@
+package rex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestAutoPackOptions(t *testing.T) {
+	obj := simpleObject{}
+	obj.Name = "Test"
+	obj.Numbers = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	b, _ := json.MarshalIndent(obj, "", "  ")
+
+	expected := `{
+  "name": "Test",
+  "location": {
+    "x": 0, "y": 0, "z": 0
+  },
+  "orientation": {
+    "x": 0, "y": 0, "z": 0, "w": 0
+  },
+  "color": { "r": 0, "g": 0, "b": 0 },
+  "numbers": [
+    1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
+    12, 13, 14, 15, 16
+  ]
+}`
+	text := AutoPack(string(b), AutoPackOptions{MaxWidth: 40})
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" MaxWidth", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" MaxWidth")
+	}
+
+	expected = `{
+  "name": "Test",
+  "location": {
+    "x": 0,
+    "y": 0,
+    "z": 0
+  },
+  "orientation": { "x": 0, "y": 0, "z": 0, "w": 0 },
+  "color": {
+    "r": 0,
+    "g": 0,
+    "b": 0
+  },
+  "numbers": [ 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16 ]
+}`
+	text = AutoPack(string(b), AutoPackOptions{
+		MaxWidth:            60,
+		Pack:                SelectNamed('{', "orientation"),
+		FlattenScalarArrays: true,
+	})
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Pack", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Pack")
+	}
+}
+
+func TestAutoPackRunes(t *testing.T) {
+	// the MaxWidth counts runes, not bytes
+	source := `{
+  "name": "Zoë Noël",
+  "city": "Zürich"
+}`
+	expected := `{ "name": "Zoë Noël", "city": "Zürich" }`
+	text := AutoPack(source, AutoPackOptions{MaxWidth: 40})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
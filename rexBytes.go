@@ -0,0 +1,58 @@
+package rex
+
+import (
+	"regexp"
+)
+
+/*
+	[]byte versions of the cleanup functions, for use directly on the output of
+	json.MarshalIndent without converting to and from a string.  They share their code
+	with the string versions, so give the same results.
+
+	CleanerFuncBytes: TYPE
+		As CleanerFunc, but taking and returning a []byte
+
+	PackLinesBytes: CleanerFuncBytes
+	PackLinesMaxBytes:
+	RexCleanupBytes:
+	RexJSONCleanupBytes:
+	RexJSONCleanupPostBytes:
+	RemoveJSONPaddingBytes:
+	AddJSONPaddingBytes:
+		As the string versions
+
+	The returned []byte may share memory with the source.
+*/
+
+type CleanerFuncBytes func([]byte) []byte
+
+func PackLinesBytes(src []byte) []byte {
+	return packLines(src)
+}
+
+func PackLinesMaxBytes(src []byte, max int) []byte {
+	return packLinesMax(src, max)
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexCleanupBytes(src []byte, rx *regexp.Regexp, cf CleanerFuncBytes) []byte {
+	return rexCleanup(src, rx, cf)
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexJSONCleanupBytes(src []byte, rx *regexp.Regexp, cf CleanerFuncBytes) []byte {
+	return rexJSONCleanup(src, rx, cf, nil)
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexJSONCleanupPostBytes(src []byte, rx *regexp.Regexp, cf CleanerFuncBytes, post CleanerFuncBytes) []byte {
+	return rexJSONCleanup(src, rx, cf, post)
+}
+
+func RemoveJSONPaddingBytes(src []byte) ([]byte, []byte) {
+	return removeJSONPadding(src)
+}
+
+func AddJSONPaddingBytes(lead, src []byte) []byte {
+	return addJSONPadding(lead, src)
+}
@@ -0,0 +1,44 @@
+package rex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestBytesMatchStrings(t *testing.T) {
+	testObject := objects{}
+	testObject.Verticies = make([]vert, 3)
+	b, _ := json.MarshalIndent(testObject, "", "  ")
+
+	expected := RexJSONCleanup(string(b), vertsArrayRex, func(src string) string {
+		return RexJSONCleanupPost(src, UnnamedJSONObjectRex, func(s string) string {
+			return PackLinesMax(RexJSONCleanup(s, NamedJSONObjectRex, PackLines), 60)
+		}, func(s string) string {
+			return RexCleanup(s, blankLinesRex, PackLines)
+		})
+	})
+	text := string(RexJSONCleanupBytes(b, vertsArrayRex, func(src []byte) []byte {
+		return RexJSONCleanupPostBytes(src, UnnamedJSONObjectRex, func(s []byte) []byte {
+			return PackLinesMaxBytes(RexJSONCleanupBytes(s, NamedJSONObjectRex, PackLinesBytes), 60)
+		}, func(s []byte) []byte {
+			return RexCleanupBytes(s, blankLinesRex, PackLinesBytes)
+		})
+	}))
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	lead, sub := RemoveJSONPaddingBytes([]byte("    a\n    b\n"))
+	if text := string(AddJSONPaddingBytes(lead, append([]byte("\n"), sub...))); text != "\n    a\n    b\n" {
+		tst.Failed(t, dbg.IAm()+" Padding", "Padding not restored: "+text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Padding")
+	}
+}
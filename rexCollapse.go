@@ -0,0 +1,113 @@
+package rex
+
+import (
+	"strings"
+)
+
+/*
+	Collapsing the brackets of nested arrays onto shared lines.
+
+	CollapseOptions: TYPE
+		Controls CollapseNestedArrays
+			Objects:	Arrays of objects are collapsed too, as in:  [ {  },  {  } ]
+
+	CollapseNestedArrays:
+		Works on json.MarshalIndent style JSON, after any packing, where each open object /
+		array ends its line and each closing bracket starts its own.  An open array whose
+		elements are all open arrays has its brackets joined to theirs, as in:
+			"n3": [ [
+			    [ 1, 2, 3 ],
+			    [ 4, 5, 6 ]
+			  ], [
+			    [ 7, 8, 9 ]
+			] ],
+		at any depth, with a run of closing brackets indented as the outermost of them.  An
+		array holding anything else (simple values, packed or empty arrays, or objects unless
+		Objects is set) is left as it is, as are the lines inside the innermost arrays.
+		Text that is not laid out one bracket per line, including text already collapsed,
+		is returned unchanged.
+*/
+
+type CollapseOptions struct {
+	Objects bool
+}
+
+// an open object / array, by the lines of its open and close brackets
+type openBox struct {
+	array      bool
+	open       int
+	close      int
+	boxes      []*openBox // open objects / arrays held by an array
+	onlyBoxes  bool       // an array holding nothing but open objects / arrays
+	collapsing bool
+}
+
+func CollapseNestedArrays(src string, opts CollapseOptions) string {
+	lines := strings.Split(src, "\n")
+	joinNext := make([]bool, len(lines)) // line is joined by the one following it
+
+	var stack []*openBox
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		switch {
+		case isCloseLine(text):
+			if len(stack) == 0 {
+				return src // not balanced, or not laid out one bracket per line
+			}
+			box := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			box.close = i
+			if box.array && box.onlyBoxes && len(box.boxes) > 0 {
+				box.collapsing = true
+				for _, b := range box.boxes {
+					if !(b.array || opts.Objects) {
+						box.collapsing = false
+					}
+				}
+			}
+			if box.collapsing {
+				joinNext[box.open] = true
+				for _, b := range box.boxes {
+					joinNext[b.close] = true
+				}
+			}
+		case strings.HasSuffix(text, "[") || strings.HasSuffix(text, "{"):
+			box := &openBox{array: strings.HasSuffix(text, "["), open: i, onlyBoxes: true}
+			if n := len(stack); n > 0 && stack[n-1].array {
+				stack[n-1].boxes = append(stack[n-1].boxes, box)
+			}
+			stack = append(stack, box)
+		case text != "":
+			if n := len(stack); n > 0 {
+				stack[n-1].onlyBoxes = false
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return src
+	}
+
+	var result []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for ; joinNext[i] && i+1 < len(lines); i++ {
+			text, next := strings.TrimSpace(line), strings.TrimSpace(lines[i+1])
+			if isCloseLine(next) && (text[0] == ']' || text[0] == '}') {
+				line = lines[i+1][:len(lines[i+1])-len(strings.TrimLeft(lines[i+1], " \t"))] + text + " " + next
+			} else {
+				line = strings.TrimRight(line, " \t\r") + " " + next
+			}
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+// a line holding only a closing bracket, and any comma following it
+func isCloseLine(text string) bool {
+	switch strings.TrimSuffix(text, ",") {
+	case "]", "}":
+		return true
+	}
+	return false
+}
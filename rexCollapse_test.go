@@ -0,0 +1,134 @@
+package rex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+// n0 through n5 of arrayText are tested in rexArray_test.go, these are shallower versions
+func TestCollapseNestedArrays(t *testing.T) {
+	expects := []string{`{
+  "n": [ 1, 2 ]
+}`, `{
+  "n": [
+    [ 1, 2 ],
+    [ 1, 2 ]
+  ]
+}`, `{
+  "n": [ [
+      [ 1, 2 ],
+      [ 1, 2 ]
+    ], [
+      [ 1, 2 ],
+      [ 1, 2 ]
+  ] ]
+}`, `{
+  "n": [ [ [
+        [ 1, 2 ],
+        [ 1, 2 ]
+      ], [
+        [ 1, 2 ],
+        [ 1, 2 ]
+    ] ], [ [
+        [ 1, 2 ],
+        [ 1, 2 ]
+      ], [
+        [ 1, 2 ],
+        [ 1, 2 ]
+  ] ] ]
+}`}
+	var v interface{} = []int{1, 2}
+	for n, expected := range expects {
+		b, _ := json.MarshalIndent(map[string]interface{}{"n": v}, "", "  ")
+		text := CollapseNestedArrays(AutoPack(string(b), AutoPackOptions{}), CollapseOptions{})
+		if text != expected {
+			tst.Failed(t, dbg.IAm()+" n"+string('0'+rune(n)), "Expected in green, genereted in red")
+			tst.AsGreen(expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" n"+string('0'+rune(n)))
+		}
+		v = []interface{}{v, v}
+	}
+}
+
+var mixedArrays = `{
+  "mixed": [
+    [
+      { "a": 1 },
+      { "b": 2 }
+    ],
+    [
+      {
+        "c": [
+          [ 1 ],
+          [ 2 ]
+        ]
+      }
+    ],
+    [],
+    [
+    ]
+  ],
+  "objs": [
+    {
+      "s": "[",
+      "t": "]"
+    },
+    {
+      "u": "],"
+    }
+  ]
+}`
+
+func TestCollapseMixedArrays(t *testing.T) {
+	// packed objects, empty arrays and brackets in strings leave the arrays as they are
+	if text := CollapseNestedArrays(mixedArrays, CollapseOptions{}); text != mixedArrays {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(mixedArrays)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	expected := `{
+  "mixed": [
+    [
+      { "a": 1 },
+      { "b": 2 }
+    ],
+    [ {
+        "c": [
+          [ 1 ],
+          [ 2 ]
+        ]
+    } ],
+    [],
+    [
+    ]
+  ],
+  "objs": [ {
+      "s": "[",
+      "t": "]"
+    }, {
+      "u": "],"
+  } ]
+}`
+	text := CollapseNestedArrays(mixedArrays, CollapseOptions{Objects: true})
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Objects", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Objects")
+	}
+
+	if again := CollapseNestedArrays(text, CollapseOptions{Objects: true}); again != text {
+		tst.Failed(t, dbg.IAm()+" Again", "Collapsing collapsed text changed it")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Again")
+	}
+}
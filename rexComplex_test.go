@@ -116,18 +116,8 @@ func silencesCleaner(src string) string {
 }
 
 func trackCleaner(src string) string {
-	var titleRex = regexp.MustCompile(`((?s).*?"title": )((?s).*)`)
-	var artistRex = regexp.MustCompile(`((?s).*?"artist": )((?s).*)`)
-	var trkRex = regexp.MustCompile(`((?s).*?trk-stt":)((?s).*?trk-len.+?)(\n(?s).*)`)
-	var audRex = regexp.MustCompile(`((?s).*?aud-stt":)((?s).*?aud-len.+?)(\n(?s).*)`)
-	src = RexReplace(src, titleRex, func(x []string, rx *regexp.Regexp, rf RexFunc) string {
-		return x[1] + "       " + RexReplace(x[2], rx, rf)
-	})
-	src = RexReplace(src, artistRex, func(x []string, rx *regexp.Regexp, rf RexFunc) string {
-		return x[1] + "      " + RexReplace(x[2], rx, rf)
-	})
-	src = RexCleanup(src, trkRex, PackLines)
-	src = RexCleanup(src, audRex, PackLines)
+	src = AlignValues(AlignOptions{Keys: []string{"title", "artist"}, MinWidth: 16})(src)
+	src = GroupKeys(KeyGroup{Prefixes: []string{"trk-"}}, KeyGroup{Prefixes: []string{"aud-"}})(src)
 	src = RexJSONCleanup(src, NamedJSONArrayRex, silencesCleaner)
 	return src
 }
@@ -195,34 +185,11 @@ func TestComplexJSON(t *testing.T) {
     }
   ]
 }`
-	artistRex := regexp.MustCompile(`((?sm).*?^  "artist": )((?sm).*)`)
-	artistMatchRex := regexp.MustCompile(`((?sm).*?^  "artist-match": )((?sm).*)`)
-	artistSaveRex := regexp.MustCompile(`((?sm).*?^  "save-artist": )((?sm).*)`)
-	albumRex := regexp.MustCompile(`((?sm).*?^  "album": )((?sm).*)`)
-	albumMatchRex := regexp.MustCompile(`((?sm).*?^  "album-match": )((?sm).*)`)
-	albumSaveRex := regexp.MustCompile(`((?sm).*?^  "save-album": )((?sm).*)`)
 	tracksRex := regexp.MustCompile(`((?sm).*?^  "tracks": \[\n)((?s).*?)((?sm)^  \].*)`)
 
-	text := RexJSONCleanup(complexSource, tracksRex, cleanTrack)
+	text := AlignValues(AlignOptions{Keys: []string{"artist", "artist-match", "save-artist", "album", "save-album"}})(complexSource)
+	text = RexJSONCleanup(text, tracksRex, cleanTrack)
 	text = removeExtraSpaces(text)
-	if x := artistRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + "      " + x[2]
-	}
-	if x := artistMatchRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + "" + x[2]
-	}
-	if x := artistSaveRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + " " + x[2]
-	}
-	if x := albumRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + "       " + x[2]
-	}
-	if x := albumMatchRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + " " + x[2]
-	}
-	if x := albumSaveRex.FindStringSubmatch(text); x != nil {
-		text = x[1] + "  " + x[2]
-	}
 
 	if text != expected {
 		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
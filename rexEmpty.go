@@ -0,0 +1,137 @@
+package rex
+
+import (
+	"strings"
+)
+
+/*
+	Writing empty objects and arrays, and null values, one way throughout.
+
+	EmptyOptions: TYPE
+		Controls NormalizeEmpties
+			Spaced:		Empty objects / arrays become { } and [ ] rather than {} and []
+			Open:		The closing bracket goes on its own line, indented as the line of the
+						opening bracket, as the regexps of the RexJSONCleanup functions need
+			NullArrays:	Members with a null value get an empty array, as nil slices are
+						marshalled as null
+			DropNulls:	Members with a null value are removed, taking precedence over NullArrays
+			Keys:		Names of the members NullArrays and DropNulls apply to, all if none --
+						null array entries are never changed
+
+	NormalizeEmpties:
+		Returns a CleanerFunc writing every empty object / array, at any depth, as chosen by
+		the EmptyOptions, including those holding only white space, as {\n  } or [ ], and
+		those left empty by DropNulls.  The brackets and nulls found in strings are never
+		changed.  Works on whole JSON texts and on the bodies handed to a CleanerFunc.
+*/
+
+type EmptyOptions struct {
+	Spaced     bool
+	Open       bool
+	NullArrays bool
+	DropNulls  bool
+	Keys       []string
+}
+
+func NormalizeEmpties(opts EmptyOptions) CleanerFunc {
+	return func(src string) string {
+		return normalizeEmpties(src, 0, len(src), opts)
+	}
+}
+
+// normalizes the entries of the object or array body (or the whole text) src[stt:stop]
+func normalizeEmpties(src string, stt, stop int, opts EmptyOptions) string {
+	type keptEntry struct {
+		lead, text string
+		comma      bool
+		index      int
+	}
+	var (
+		kept    []keptEntry
+		body    = src[stt:stop]
+		entries = jsonEntries(body)
+		end     = 0 // offset following the last entry
+	)
+	for n, e := range entries {
+		lead := body[end:e.at]
+		end = e.at + len(e.text)
+		if n > 0 && len(kept) == 0 {
+			lead = body[:entries[0].at] // follows only dropped entries, so starts where they did
+		}
+		text := strings.TrimSuffix(e.text, ",")
+		comma := len(text) < len(e.text)
+		text = strings.TrimSpace(text)
+
+		indent := lineIndent(src, stt+e.at)
+		key, name, value := "", "", text
+		if strings.HasPrefix(text, `"`) {
+			k := skipJSONString(text, 0)
+			if i := skipJSONSpace(text, k); i < len(text) && text[i] == ':' {
+				j := skipJSONSpace(text, i+1)
+				key, name, value = text[:j], jsonUnquote(text[:k]), text[j:]
+			}
+		}
+
+		nulls := key != "" && (len(opts.Keys) == 0 || indexOf(opts.Keys, name) >= 0)
+		switch {
+		case value == "null" && nulls && opts.DropNulls:
+			continue
+		case value == "null" && nulls && opts.NullArrays:
+			value = emptyText('[', indent, opts)
+		case strings.HasPrefix(value, "{") || strings.HasPrefix(value, "["):
+			if close := matchingClose(value); close == len(value)-1 {
+				at := stt + e.at + len(key) // offset of value in src
+				if inner := normalizeEmpties(src, at+1, at+close, opts); strings.TrimSpace(inner) == "" {
+					value = emptyText(value[0], indent, opts)
+				} else {
+					value = value[:1] + inner + value[close:]
+				}
+			}
+		}
+		kept = append(kept, keptEntry{lead, key + value, comma, n})
+	}
+
+	var result strings.Builder
+	for n, k := range kept {
+		result.WriteString(k.lead)
+		result.WriteString(k.text)
+		if k.comma && (n < len(kept)-1 || k.index == len(entries)-1) {
+			result.WriteString(",")
+		}
+	}
+	result.WriteString(body[end:])
+	return result.String()
+}
+
+// the empty object / array as chosen by the options, indent being that of its line
+func emptyText(open byte, indent string, opts EmptyOptions) string {
+	close := "]"
+	if open == '{' {
+		close = "}"
+	}
+	switch {
+	case opts.Open:
+		return string(open) + "\n" + indent + close
+	case opts.Spaced:
+		return string(open) + " " + close
+	}
+	return string(open) + close
+}
+
+// offset of the bracket closing the one starting src, -1 if none
+func matchingClose(src string) int {
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			i = skipJSONString(src, i) - 1
+		case '{', '[':
+			depth += 1
+		case '}', ']':
+			if depth -= 1; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
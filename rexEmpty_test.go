@@ -0,0 +1,137 @@
+package rex
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+var emptiesSource = `{
+  "a": {},
+  "b": [
+  ],
+  "c": { },
+  "s": "{ } [ ] null",
+  "n": null,
+  "deep": [ { "x": [ [], {
+    } ], "y": null } ],
+  "list": [ null, 1 ],
+  "last": null
+}`
+
+func TestNormalizeEmpties(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     EmptyOptions
+		expected string
+	}{
+		{"Compact", EmptyOptions{}, `{
+  "a": {},
+  "b": [],
+  "c": {},
+  "s": "{ } [ ] null",
+  "n": null,
+  "deep": [ { "x": [ [], {} ], "y": null } ],
+  "list": [ null, 1 ],
+  "last": null
+}`},
+		{"Spaced", EmptyOptions{Spaced: true}, `{
+  "a": { },
+  "b": [ ],
+  "c": { },
+  "s": "{ } [ ] null",
+  "n": null,
+  "deep": [ { "x": [ [ ], { } ], "y": null } ],
+  "list": [ null, 1 ],
+  "last": null
+}`},
+		{"Open", EmptyOptions{Open: true}, `{
+  "a": {
+  },
+  "b": [
+  ],
+  "c": {
+  },
+  "s": "{ } [ ] null",
+  "n": null,
+  "deep": [ { "x": [ [
+  ], {
+  } ], "y": null } ],
+  "list": [ null, 1 ],
+  "last": null
+}`},
+		{"NullArrays", EmptyOptions{NullArrays: true}, `{
+  "a": {},
+  "b": [],
+  "c": {},
+  "s": "{ } [ ] null",
+  "n": [],
+  "deep": [ { "x": [ [], {} ], "y": [] } ],
+  "list": [ null, 1 ],
+  "last": []
+}`},
+		{"NullArrays Keys", EmptyOptions{NullArrays: true, Keys: []string{"n"}}, `{
+  "a": {},
+  "b": [],
+  "c": {},
+  "s": "{ } [ ] null",
+  "n": [],
+  "deep": [ { "x": [ [], {} ], "y": null } ],
+  "list": [ null, 1 ],
+  "last": null
+}`},
+		{"DropNulls", EmptyOptions{DropNulls: true}, `{
+  "a": {},
+  "b": [],
+  "c": {},
+  "s": "{ } [ ] null",
+  "deep": [ { "x": [ [], {} ] } ],
+  "list": [ null, 1 ]
+}`},
+		{"DropNulls Keys", EmptyOptions{DropNulls: true, Keys: []string{"y", "last"}}, `{
+  "a": {},
+  "b": [],
+  "c": {},
+  "s": "{ } [ ] null",
+  "n": null,
+  "deep": [ { "x": [ [], {} ] } ],
+  "list": [ null, 1 ]
+}`},
+	}
+	for _, test := range tests {
+		text := NormalizeEmpties(test.opts)(emptiesSource)
+		if text != test.expected {
+			tst.Failed(t, dbg.IAm()+" "+test.name, "Expected in green, genereted in red")
+			tst.AsGreen(test.expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+test.name)
+		}
+	}
+}
+
+func TestNormalizeEmptiesBody(t *testing.T) {
+	// as a CleanerFunc, objects left empty by DropNulls are empty objects
+	expected := `{
+  "kept": { "b": 1 },
+  "gone": {},
+  "also": {}
+}`
+	text := RexJSONCleanup(`{
+  "kept": { "a": null, "b": 1, "c": null },
+  "gone": { "a": null, "b": null },
+  "also": {
+    "a": null
+  }
+}`, UnnamedJSONObjectRex, func(s string) string {
+		return "\n" + NormalizeEmpties(EmptyOptions{DropNulls: true})(s)
+	})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
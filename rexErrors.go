@@ -0,0 +1,91 @@
+package rex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+/*
+	Versions of the cleanup functions that can report failure.
+
+	CleanerFuncE: TYPE
+		A CleanerFunc that can fail, returning an error rather than the cleaned string
+
+	ErrNoMatch: VAR
+		Returned by RequireMatch when the regexp does not match
+
+	CleanupError: TYPE
+		Wraps the error returned by a CleanerFuncE with where in the source text the text
+		given to the CleanerFuncE (x[2]) started: its byte Offset and its Line, counted from 1
+
+	RexCleanupE:
+		Like RexCleanup, but stops at the first error from the CleanerFuncE, returning the
+		source text unchanged and the error wrapped in a CleanupError
+	RexJSONCleanupE:
+		Like RexJSONCleanup, with errors handled as RexCleanupE
+	RexJSONCleanupPostE:
+		Like RexJSONCleanupPost, with errors from either CleanerFuncE handled as RexCleanupE
+
+	RequireMatch:
+		Returns ErrNoMatch if the regexp does not match the text, for callers that require
+		a cleanup to apply -- the cleanup functions themselves return the text unchanged
+*/
+
+type CleanerFuncE func(string) (string, error)
+
+var ErrNoMatch = errors.New("rex: no match")
+
+type CleanupError struct {
+	Offset int
+	Line   int
+	Err    error
+}
+
+func (e *CleanupError) Error() string {
+	return fmt.Sprintf("rex: cleanup at offset %d (line %d): %v", e.Offset, e.Line, e.Err)
+}
+
+func (e *CleanupError) Unwrap() error {
+	return e.Err
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexCleanupE(src string, rx *regexp.Regexp, cf CleanerFuncE) (string, error) {
+	return rexCleanupE(src, rx, cf)
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexJSONCleanupE(src string, rx *regexp.Regexp, cf CleanerFuncE) (string, error) {
+	return RexJSONCleanupPostE(src, rx, cf, nil)
+}
+
+// regexp must extract data as x[1]: lead data  x[2]: SubBlock  x[3]: tail data
+func RexJSONCleanupPostE(src string, rx *regexp.Regexp, cf CleanerFuncE, post CleanerFuncE) (string, error) {
+	return rexCleanupE(src, rx, func(sub string) (string, error) {
+		lead, sub := RemoveJSONPadding(sub)
+		text, err := cf(sub)
+		if err != nil {
+			return "", err
+		}
+		text = AddJSONPadding(lead, text)
+		if post != nil {
+			return post(text)
+		}
+		return text, nil
+	})
+}
+
+func rexCleanupE(src string, rx *regexp.Regexp, cf CleanerFuncE) (string, error) {
+	if err := missingGroups(rx); err != nil {
+		return src, err
+	}
+	return rexCleanupCore(src, rx, cf)
+}
+
+func RequireMatch(src string, rx *regexp.Regexp) error {
+	if !rx.MatchString(src) {
+		return ErrNoMatch
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package rex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestRexJSONCleanupE(t *testing.T) {
+	tracksRex := regexp.MustCompile(`((?sm).*?^  "tracks": \[\n)((?s).*?)((?sm)^  \].*)`)
+	trackRex := regexp.MustCompile(`((?sm).*?^    {)\n((?s).*?)((?sm)^    }.*)`)
+	noSilences := func(src string) (string, error) {
+		if strings.Contains(src, "silences") {
+			return "", errors.New("silences not allowed")
+		}
+		return "\n" + PackLines(src), nil
+	}
+
+	// no errors gives the same as RexJSONCleanup
+	pack := func(src string) (string, error) { return "\n" + PackLines(src), nil }
+	expected := RexJSONCleanup(complexSource, trackRex, func(src string) string { return "\n" + PackLines(src) })
+	text, err := RexJSONCleanupE(complexSource, trackRex, pack)
+	if err != nil || text != expected || text == complexSource {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// the 5th track is the one with silences, its body starts on line 56
+	text, err = RexJSONCleanupE(complexSource, trackRex, noSilences)
+	var ce *CleanupError
+	msg := `rex: cleanup at offset 1395 (line 56): silences not allowed`
+	if !errors.As(err, &ce) || err.Error() != msg || text != complexSource ||
+		!strings.HasPrefix(complexSource[ce.Offset:], "      \"title\": \"Kick The Foobar\"") {
+		tst.Failed(t, dbg.IAm()+" Error", "Expected in green, genereted in red")
+		tst.AsGreen(msg)
+		tst.AsRed(fmt.Sprint(err))
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Error")
+	}
+
+	if _, err = RexJSONCleanupPostE(complexSource, tracksRex, pack, noSilences); !errors.As(err, &ce) || ce.Line != 14 {
+		tst.Failed(t, dbg.IAm()+" Post", "Post error not reported")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Post")
+	}
+
+	// no match is not an error, unless required
+	if text, err = RexCleanupE(complexSource, NamedJSONObjectRex, pack); err != nil || text != complexSource ||
+		!errors.Is(RequireMatch(complexSource, NamedJSONObjectRex), ErrNoMatch) || RequireMatch(complexSource, tracksRex) != nil {
+		tst.Failed(t, dbg.IAm()+" NoMatch", "ErrNoMatch not returned")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" NoMatch")
+	}
+}
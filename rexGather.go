@@ -0,0 +1,98 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Ready made RexGather collectors, looping rather than recursing like a GatherFunc.
+
+	Each search continues from the start of the regexp's tail group, or after the match if
+	it has no tail group, as RexGatherMatch.  The group is the number
+	of the group to collect, e.g. 2 for the x[1] / x[2] / x[3] format.
+
+	GatherAll:
+		Returns the text of the group for every match, in order
+	GatherUnique:
+		Returns the text of the group for every match, without repeats, in the order first seen
+	GatherCounts:
+		Returns how many times each text of the group was found
+	GatherJoined:
+		Returns the texts of the group for every match joined by sep, without a trailing sep
+
+	Gathered: TYPE
+		Text gathered with where it was found: its byte Offset, and its Line and Column
+		counted from 1, the Column in runes
+
+	Gathered.String:
+		Returns the position as line:column, as used in file:line:col diagnostics
+
+	GatherPositions:
+		Returns the text of the group for every match, with its position
+*/
+
+func GatherAll(src string, rx *regexp.Regexp, group int) []string {
+	var found []string
+	RexGatherMatch(src, rx, func(m *Match) {
+		found = append(found, m.Sub(group))
+	})
+	return found
+}
+
+func GatherUnique(src string, rx *regexp.Regexp, group int) []string {
+	var found []string
+	seen := map[string]bool{}
+	RexGatherMatch(src, rx, func(m *Match) {
+		if g := m.Sub(group); !seen[g] {
+			seen[g] = true
+			found = append(found, g)
+		}
+	})
+	return found
+}
+
+func GatherCounts(src string, rx *regexp.Regexp, group int) map[string]int {
+	counts := map[string]int{}
+	RexGatherMatch(src, rx, func(m *Match) {
+		counts[m.Sub(group)] += 1
+	})
+	return counts
+}
+
+func GatherJoined(src string, rx *regexp.Regexp, group int, sep string) string {
+	return strings.Join(GatherAll(src, rx, group), sep)
+}
+
+type Gathered struct {
+	Text   string
+	Offset int
+	Line   int
+	Column int
+}
+
+func (g Gathered) String() string {
+	return fmt.Sprintf("%d:%d", g.Line, g.Column)
+}
+
+func GatherPositions(src string, rx *regexp.Regexp, group int) []Gathered {
+	var found []Gathered
+	line, col, counted := 1, 0, 0 // position of offset counted, matches only move forward
+	RexGatherMatch(src, rx, func(m *Match) {
+		at, _ := m.SubSpan(group)
+		if at < 0 {
+			return
+		}
+		if i := strings.LastIndex(src[counted:at], "\n"); i >= 0 {
+			line += strings.Count(src[counted:at], "\n")
+			col = utf8.RuneCountInString(src[counted+i+1 : at])
+		} else {
+			col += utf8.RuneCountInString(src[counted:at])
+		}
+		counted = at
+		found = append(found, Gathered{Text: m.Sub(group), Offset: at, Line: line, Column: col + 1})
+	})
+	return found
+}
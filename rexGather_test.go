@@ -1,6 +1,7 @@
 package rex
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
@@ -66,3 +67,87 @@ func TestRexGatherUniqueLC(t *testing.T) {
 		tst.Passed(t, "", dbg.IAm())
 	}
 }
+
+func TestGatherCollectors(t *testing.T) {
+	expected := "apple banana cherry date fig grape apple banana cherry date fig grape berry mellon orange berry mellon orange"
+	if text := GatherJoined(wordtext, lcwordRex, 2, " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" Joined", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Joined")
+	}
+
+	if text := strings.Join(GatherAll(wordtext, lcwordRex, 2), " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" All", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" All")
+	}
+
+	expected = "apple banana cherry date fig grape berry mellon orange"
+	if text := strings.Join(GatherUnique(wordtext, lcwordRex, 2), " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" Unique", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Unique")
+	}
+
+	counts := GatherCounts(wordtext, lcwordRex, 2)
+	if len(counts) != 9 || counts["apple"] != 2 || counts["orange"] != 2 || counts["Apple"] != 0 {
+		tst.Failed(t, dbg.IAm()+" Counts", "Wrong counts")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Counts")
+	}
+
+	// a regexp without a tail group
+	expected = "Apple Banana Cherry Date Fig Grape"
+	if text := GatherJoined(wordtext, regexp.MustCompile(`\b[A-Z][a-z]+`), 0, " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" NoTail", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" NoTail")
+	}
+
+	// an empty match at the end, adjacent to the last match, is not gathered
+	if found := GatherAll("abc", regexp.MustCompile(`c?$`), 0); len(found) != 1 || found[0] != "c" {
+		tst.Failed(t, dbg.IAm()+" EmptyEnd", fmt.Sprintf("Expected [c], genereted %q", found))
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" EmptyEnd")
+	}
+}
+
+func TestGatherPositions(t *testing.T) {
+	// columns count runes, so the curly quotes on line 3 take a single column
+	expected := `1:1 apple, 1:9 banana, 1:18 cherry, 3:2 apple, 3:10 banana, 3:19 cherry, 7:3 berry, 8:4 berry`
+	var found []string
+	for _, g := range GatherPositions(wordtext, regexp.MustCompile(`(?s)(.*?)\b(apple|banana|cherry|berry)\b(.*)`), 2) {
+		if wordtext[g.Offset:g.Offset+len(g.Text)] == g.Text {
+			found = append(found, g.String()+" "+g.Text)
+		}
+	}
+	if text := strings.Join(found, ", "); text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// each word boundary once, as regexp.FindAllStringIndex
+	expected = "1:1 1:2 1:3 1:4"
+	found = nil
+	for _, g := range GatherPositions("a b", regexp.MustCompile(`\b`), 0) {
+		found = append(found, g.String())
+	}
+	if text := strings.Join(found, " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" Boundary", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Boundary")
+	}
+}
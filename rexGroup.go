@@ -0,0 +1,99 @@
+package rex
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*
+	Grouping of related object members onto one line.
+
+	KeyGroup: TYPE
+		Names of members that belong together, a member is in the group if its name:
+			Names:		Is one of these
+			Prefixes:	Starts with one of these
+			Rex:		Is matched by this regexp
+
+	GroupKeys:
+		Returns a CleanerFunc that joins each run of members of the same group onto one line
+		in every object, as in:
+			"trk-stt": "0:00", "trk-end": "3:42'659!48", "trk-len": "3:42.733",
+			"aud-stt": "0:00'244", "aud-end": "3:39'227!48", "aud-len": "3:38.982"
+		Members are never moved, a group's members separated by other members are joined in
+		separate runs.  Only members on a single line are joined.  The text can be a whole
+		document or the body of an object as handed to a CleanerFunc, and bad JSON, as from
+		an unterminated string, is never a panic.
+*/
+
+type KeyGroup struct {
+	Names    []string
+	Prefixes []string
+	Rex      *regexp.Regexp
+}
+
+func (g KeyGroup) match(key string) bool {
+	for _, n := range g.Names {
+		if key == n {
+			return true
+		}
+	}
+	for _, p := range g.Prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return g.Rex != nil && g.Rex.MatchString(key)
+}
+
+func GroupKeys(groups ...KeyGroup) CleanerFunc {
+	return func(src string) string {
+		var gaps [][2]int // white space between members to become a single space
+		addGaps := func(stt, end int) {
+			last, lastGroup := -1, -1
+			for _, e := range jsonEntries(src[stt:end]) {
+				g := keyGroup(groups, e)
+				if g >= 0 && g == lastGroup && last <= stt+e.at && strings.Contains(src[last:stt+e.at], "\n") {
+					gaps = append(gaps, [2]int{last, stt + e.at})
+				}
+				last, lastGroup = stt+e.at+len(e.text), g
+			}
+		}
+		addGaps(0, len(src))
+		for _, c := range ScanJSON(src) {
+			if c.Kind == '{' && c.Close > 0 {
+				addGaps(c.Open+1, c.Close)
+			}
+		}
+		sort.Slice(gaps, func(i, j int) bool { return gaps[i][0] < gaps[j][0] })
+		var result strings.Builder
+		done := 0
+		for _, g := range gaps {
+			if g[0] < done || g[1] < g[0] {
+				continue // from unbalanced text, overlapping a gap already made
+			}
+			result.WriteString(src[done:g[0]] + " ")
+			done = g[1]
+		}
+		result.WriteString(src[done:])
+		return result.String()
+	}
+}
+
+// index of the group of a single line member, -1 if none or not a single line member
+func keyGroup(groups []KeyGroup, e jsonEntry) int {
+	if strings.Contains(e.text, "\n") || e.text[0] != '"' {
+		return -1
+	}
+	end := skipJSONString(e.text, 0)
+	if j := skipJSONSpace(e.text, end); j >= len(e.text) || e.text[j] != ':' {
+		return -1
+	}
+	key := jsonUnquote(e.text[:end])
+	for i, g := range groups {
+		if g.match(key) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestGroupKeys(t *testing.T) {
+	source := `{
+  "title": "Kick The Foobar",
+  "trk-stt": "16:20'180",
+  "trk-end": "24:43'899!48",
+  "number": 5,
+  "trk-len": "8:23.800",
+  "stt": 1,
+  "end": 2,
+  "silence": {
+    "stt": "22:13'395!32",
+    "end": "22:13'742!20",
+    "gap": 12
+  },
+  "x": 1,
+  "y": 2,
+  "z": {
+    "x": 0
+  }
+}`
+	expected := `{
+  "title": "Kick The Foobar",
+  "trk-stt": "16:20'180", "trk-end": "24:43'899!48",
+  "number": 5,
+  "trk-len": "8:23.800",
+  "stt": 1, "end": 2,
+  "silence": {
+    "stt": "22:13'395!32", "end": "22:13'742!20",
+    "gap": 12
+  },
+  "x": 1, "y": 2,
+  "z": {
+    "x": 0
+  }
+}`
+	text := GroupKeys(
+		KeyGroup{Prefixes: []string{"trk-"}},
+		KeyGroup{Names: []string{"stt", "end"}},
+		KeyGroup{Rex: regexp.MustCompile(`^[xyz]$`)},
+	)(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestGroupKeysBadJSON(t *testing.T) {
+	// unterminated strings and unbalanced brackets, anywhere in the text
+	group := GroupKeys(KeyGroup{Prefixes: []string{"trk-", "aud-"}}, KeyGroup{Names: []string{"stt", "end"}})
+	panicked := ""
+	for i := 0; i < len(complexSource) && panicked == ""; i += 3 {
+		for _, bad := range []string{`"`, `{`, `}`, `]`, ""} {
+			src := complexSource[:i] + bad + complexSource[i:]
+			if bad == "" {
+				src = complexSource[i:]
+			}
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked = fmt.Sprintf("%q at %d: %v", bad, i, r)
+					}
+				}()
+				group(src)
+			}()
+		}
+	}
+	if panicked != "" {
+		tst.Failed(t, dbg.IAm(), "Panicked on "+panicked)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
@@ -0,0 +1,165 @@
+package rex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+	Layout hints given by struct tags, used by MarshalPacked and Encoder.
+
+	A `rex:"..."` tag next to the `json:"..."` tag of a field says how the field's object or
+	array is to be laid out, whatever the Style does:
+		rex:"inline"	The object / array is put on one line, as in:
+							"location": { "x": 0, "y": 0, "z": 0 },
+		rex:"rows"		Each entry of the array (or member of the object) is put on its own line:
+							"verts": [
+							  { "p": { "x": 0, "y": 0, "z": 0 }, "c": { "r": 0, "g": 0, "b": 0 } },
+							  { "p": { "x": 0, "y": 0, "z": 0 }, "c": { "r": 0, "g": 0, "b": 0 } }
+							]
+	The hints apply wherever the field is found, including inside of arrays and maps.  Any
+	other hint is an error for MarshalPacked and Encoder.Encode.
+
+	ApplyHints:
+		Applies the hints found in the type of v to JSON marshalled from v, ignoring any
+		unknown hints
+*/
+
+// a layout hint and the path to the fields it applies to, "*" matching any entry
+type layoutHint struct {
+	path []string
+	hint string
+}
+
+func ApplyHints(src string, v interface{}) string {
+	hints, _ := valueHints(v)
+	return applyHints(src, hints)
+}
+
+// the hints found in the type of v, with an error for the first unknown one
+func valueHints(v interface{}) ([]layoutHint, error) {
+	hints := typeHints(reflect.TypeOf(v), nil, nil, map[reflect.Type]bool{})
+	for _, h := range hints {
+		if h.hint != "inline" && h.hint != "rows" {
+			return hints, fmt.Errorf("rex: unknown layout hint %q for %s", h.hint, strings.Join(h.path, "."))
+		}
+	}
+	return hints, nil
+}
+
+func applyHints(src string, hints []layoutHint) string {
+	if len(hints) == 0 {
+		return src
+	}
+	cs := ScanJSON(src)
+	var result strings.Builder
+	done := 0
+	for i, c := range cs {
+		if c.Close < 0 || c.Open < done {
+			continue
+		}
+		hint := matchHint(hints, containerPath(cs, i))
+		if hint == "" {
+			continue
+		}
+		items := parseJSON(src[c.Open+1 : c.Close])
+		if len(items) == 0 {
+			continue
+		}
+		result.WriteString(src[done:c.Open])
+		switch hint {
+		case "inline":
+			result.WriteString((&jsonNode{kind: c.Kind, items: items}).flat())
+		case "rows":
+			lead, rowLead := rowIndents(src, c)
+			result.WriteByte(c.Kind)
+			for j, item := range items {
+				if j > 0 {
+					result.WriteByte(',')
+				}
+				result.WriteString("\n" + rowLead + item.flat())
+			}
+			result.WriteString("\n" + lead + string(src[c.Close]))
+		default:
+			result.WriteString(src[c.Open : c.Close+1])
+		}
+		done = c.Close + 1
+	}
+	result.WriteString(src[done:])
+	return result.String()
+}
+
+// collects the hints from the tags of t and any types it holds
+func typeHints(t reflect.Type, path []string, hints []layoutHint, seen map[reflect.Type]bool) []layoutHint {
+	if t == nil {
+		return hints
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeHints(t.Elem(), append(path, "*"), hints, seen)
+	case reflect.Struct:
+	default:
+		return hints
+	}
+	if seen[t] { // recursive types
+		return hints
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		sub := path
+		if name != "" || !f.Anonymous { // embedded structs without a name add their fields
+			if name == "" {
+				name = f.Name
+			}
+			sub = append(path[:len(path):len(path)], name)
+			if hint := f.Tag.Get("rex"); hint != "" {
+				hints = append(hints, layoutHint{sub, hint})
+			}
+		}
+		hints = typeHints(f.Type, sub, hints, seen)
+	}
+	return hints
+}
+
+func matchHint(hints []layoutHint, path []string) string {
+	for _, h := range hints {
+		if len(h.path) != len(path) {
+			continue
+		}
+		match := true
+		for i, p := range h.path {
+			match = match && (p == "*" || p == path[i])
+		}
+		if match {
+			return h.hint
+		}
+	}
+	return ""
+}
+
+// returns the names / indexes leading from the outermost container to cs[i]
+func containerPath(cs []Container, i int) []string {
+	var path []string
+	for ; cs[i].Parent >= 0; i = cs[i].Parent {
+		if cs[i].Key != "" {
+			path = append(path, cs[i].Key)
+		} else {
+			path = append(path, strconv.Itoa(cs[i].Index))
+		}
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
@@ -0,0 +1,98 @@
+package rex
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+type (
+	hintedVert struct {
+		P pt    `json:"p" rex:"inline"`
+		C color `json:"c" rex:"inline"`
+	}
+	hintedMesh struct {
+		Name   string                `json:"name"`
+		Origin pt                    `json:"origin" rex:"inline"`
+		Verts  []hintedVert          `json:"verts" rex:"rows"`
+		Marks  map[string]hintedVert `json:"marks"`
+	}
+)
+
+func TestApplyHints(t *testing.T) {
+	expected := `{
+  "name": "mesh",
+  "origin": { "x": 0, "y": 0, "z": 0 },
+  "verts": [
+    { "p": { "x": 1, "y": 0, "z": 0 }, "c": { "r": 255, "g": 0, "b": 0 } },
+    { "p": { "x": 0, "y": 1, "z": 0 }, "c": { "r": 0, "g": 255, "b": 0 } }
+  ],
+  "marks": {
+    "top": {
+      "p": { "x": 0, "y": 0, "z": 1 },
+      "c": { "r": 0, "g": 0, "b": 255 }
+    }
+  }
+}`
+	mesh := hintedMesh{
+		Name: "mesh",
+		Verts: []hintedVert{
+			{pt{X: 1}, color{R: 255}},
+			{pt{Y: 1}, color{G: 255}},
+		},
+		Marks: map[string]hintedVert{"top": {pt{Z: 1}, color{B: 255}}},
+	}
+	b, err := MarshalPacked(mesh, IndentStyle)
+	if text := string(b); err != nil || text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// hints have the last say over the Style
+	expected = `[
+  {
+    "name": "mesh",
+    "origin": { "x": 0, "y": 0, "z": 0 },
+    "verts": [
+      { "p": { "x": 1, "y": 0, "z": 0 }, "c": { "r": 255, "g": 0, "b": 0 } },
+      { "p": { "x": 0, "y": 1, "z": 0 }, "c": { "r": 0, "g": 255, "b": 0 } }
+    ],
+    "marks": {
+      "top": {
+        "p": { "x": 0, "y": 0, "z": 1 },
+        "c": { "r": 0, "g": 0, "b": 255 }
+      }
+    }
+  }
+]`
+	b, err = MarshalPacked([]hintedMesh{mesh}, PackedStyle)
+	if text := string(b); err != nil || text != expected {
+		tst.Failed(t, dbg.IAm()+" Packed", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Packed")
+	}
+}
+
+func TestUnknownHint(t *testing.T) {
+	// a misspelled hint is an error rather than silently ignored
+	v := struct {
+		Verts []pt `json:"verts" rex:"row"`
+	}{Verts: []pt{{}}}
+	expected := `rex: unknown layout hint "row" for verts`
+	_, err := MarshalPacked(v, PackedStyle)
+	if text := fmt.Sprint(err); text != expected || NewEncoder(io.Discard, PackedStyle).Encode(v) == nil {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
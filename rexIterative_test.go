@@ -0,0 +1,86 @@
+package rex
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+// the original recursive versions, kept to compare the output and the speed
+
+func recursivePackLines(src string) string {
+	src = RexReplace(src, packRex, func(x []string, rx *regexp.Regexp, rf RexFunc) string {
+		return x[1] + " " + RexReplace(x[2], rx, rf)
+	})
+	return " " + src
+}
+
+func recursiveRexJSONCleanup(src string, rx *regexp.Regexp, cf CleanerFunc) string {
+	if x := rx.FindStringSubmatch(src); x != nil {
+		lead, sub := RemoveJSONPadding(x[2])
+		return x[1] + AddJSONPadding(lead, cf(sub)) + recursiveRexJSONCleanup(x[3], rx, cf)
+	}
+	return src
+}
+
+func pointsText(n int) string {
+	b, _ := json.MarshalIndent(make([]pt, n), "", "  ")
+	return string(b)
+}
+
+func packPoints(src string) string {
+	return RexJSONCleanup(src, UnnamedJSONArrayRex, func(s string) string {
+		return "\n" + RexJSONCleanup(s, UnnamedJSONObjectRex, PackLines)
+	})
+}
+
+func recursivePackPoints(src string) string {
+	return recursiveRexJSONCleanup(src, UnnamedJSONArrayRex, func(s string) string {
+		return "\n" + recursiveRexJSONCleanup(s, UnnamedJSONObjectRex, recursivePackLines)
+	})
+}
+
+func TestIterativeMatchesRecursive(t *testing.T) {
+	src := pointsText(300)
+	expected := recursivePackPoints(src)
+	text := packPoints(src)
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Iterative output differs from recursive output")
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	expected = CollapseBlankLines(0)(testText + "\n\n  \n")
+	text = RexReplaceEach(testText+"\n\n  \n", blankLinesRex, func(x []string) (string, string) {
+		return x[1], x[2]
+	})
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" RexReplaceEach", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" RexReplaceEach")
+	}
+}
+
+func BenchmarkPackPoints(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000, 100000} {
+		src := pointsText(n)
+		b.Run(fmt.Sprintf("iterative-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				packPoints(src)
+			}
+		})
+		if n <= 1000 { // the recursive version is quadratic, larger sizes take far too long
+			b.Run(fmt.Sprintf("recursive-%d", n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					recursivePackPoints(src)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Width budgeted layout of JSON.
+
+	LayoutOptions: TYPE
+		Controls the Layout of the JSON
+			Width:		Column budget for each line, 0 for no limit
+			Indent:		Indentation added for each level, "  " if empty
+
+	Layout:
+		Re-formats any JSON (compact json.Marshal output or json.MarshalIndent output): each
+		object / array is placed on one line if it fits within the Width, otherwise it is
+		broken over several lines with each of its entries placed on its own line, where
+		they are tried in the same way.  The trailing ',' of an entry counts toward the Width.
+		An array of small objects ends up with one object per line, as in:
+			"silences": [
+			  { "stt": "22:13'395!32", "end": "22:13'742!20" },
+			  { "stt": "22:15'102!08", "end": "22:16'4!42" }
+			]
+		Bad JSON is tolerated: a closing } or ] without an opening one is dropped.
+*/
+
+type LayoutOptions struct {
+	Width  int
+	Indent string
+}
+
+func Layout(src string, opts LayoutOptions) string {
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+	var lines []string
+	for _, n := range parseJSON(src) {
+		lines = opts.layout(lines, n, "", "")
+	}
+	result := strings.Join(lines, "\n")
+	if strings.HasSuffix(src, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+// appends the lines for n, indented by lead and followed by suffix
+func (o LayoutOptions) layout(lines []string, n *jsonNode, lead, suffix string) []string {
+	line := lead + n.flat() + suffix
+	if n.kind == 0 || len(n.items) == 0 || o.Width <= 0 || utf8.RuneCountInString(line) <= o.Width {
+		return append(lines, line)
+	}
+	lines = append(lines, lead+n.head())
+	for i, c := range n.items {
+		comma := ","
+		if i == len(n.items)-1 {
+			comma = ""
+		}
+		lines = o.layout(lines, c, lead+o.Indent, comma)
+	}
+	return append(lines, lead+string(n.closer())+suffix)
+}
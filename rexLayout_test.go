@@ -0,0 +1,84 @@
+package rex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestLayoutWidth(t *testing.T) {
+	source := `{"title":"Kick The Foobar","number":5,"location":{"x":0,"y":0,"z":0},` +
+		`"silences":[{"stt":"22:13'395!32","end":"22:13'742!20"},{"stt":"22:15'102!08","end":"22:16'4!42"},` +
+		`{"stt":"23:37'217!22","end":"23:38'193!28"}],"empty":[],"none":{}}`
+	expected := `{
+  "title": "Kick The Foobar",
+  "number": 5,
+  "location": { "x": 0, "y": 0, "z": 0 },
+  "silences": [
+    { "stt": "22:13'395!32", "end": "22:13'742!20" },
+    { "stt": "22:15'102!08", "end": "22:16'4!42" },
+    { "stt": "23:37'217!22", "end": "23:38'193!28" }
+  ],
+  "empty": [],
+  "none": {}
+}`
+	text := Layout(source, LayoutOptions{Width: 60})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestLayoutRunes(t *testing.T) {
+	// the Width counts runes, not bytes
+	expected := `{ "name": "Zoë Noël", "city": "Zürich" }`
+	text := Layout(`{"name":"Zoë Noël","city":"Zürich"}`, LayoutOptions{Width: 40})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestLayoutMarshalIndent(t *testing.T) {
+	expected := `{
+    "name": "Test",
+    "location": { "x": 0, "y": 0, "z": 0 },
+    "orientation": {
+        "x": 0,
+        "y": 0,
+        "z": 0,
+        "w": 0
+    },
+    "color": { "r": 0, "g": 0, "b": 0 },
+    "numbers": [ 1, 2, 3 ]
+}`
+	obj := simpleObject{}
+	obj.Name = "Test"
+	obj.Numbers = []int{1, 2, 3}
+	b, _ := json.MarshalIndent(obj, "", "  ")
+	text := Layout(string(b), LayoutOptions{Width: 45, Indent: "    "})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+	// a stray closer is skipped, not everything after it
+	expected = "{ \"a\": 1 }\n[ 2 ]"
+	if text = Layout(`} {"a":1} ] [2]`, LayoutOptions{Width: 45}); text != expected {
+		tst.Failed(t, dbg.IAm()+" Stray", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Stray")
+	}
+}
@@ -0,0 +1,81 @@
+package rex
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+/*
+	Marshalling straight to packed JSON.
+
+	MarshalPacked:
+		Does a json.MarshalIndent with the Style's Indent, then applies the Style and any
+		`rex:"..."` layout hints in v's struct tags (see ApplyHints), an unknown hint being
+		an error
+
+	Encoder: TYPE
+		Like json.Encoder, but writes each value laid out with a Style
+
+	NewEncoder:
+		Creates an Encoder writing to w using the Style
+
+	Encoder.SetIndent:
+		As json.Encoder, replaces the Style's Indent and adds a prefix to each line
+
+	Encoder.SetEscapeHTML:
+		As json.Encoder, HTML characters are escaped unless turned off
+
+	Encoder.Encode:
+		Writes the JSON for v, laid out with the Style and v's layout hints, followed by a newline
+*/
+
+func MarshalPacked(v interface{}, style Style) ([]byte, error) {
+	hints, err := valueHints(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(v, "", style.indent())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(applyHints(style.Apply(string(b)), hints)), nil
+}
+
+type Encoder struct {
+	w          io.Writer
+	style      Style
+	prefix     string
+	indent     string
+	escapeHTML bool
+}
+
+func NewEncoder(w io.Writer, style Style) *Encoder {
+	return &Encoder{w: w, style: style, indent: style.indent(), escapeHTML: true}
+}
+
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix, e.indent = prefix, indent
+}
+
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.escapeHTML = on
+}
+
+func (e *Encoder) Encode(v interface{}) error {
+	hints, err := valueHints(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent(e.prefix, e.indent)
+	enc.SetEscapeHTML(e.escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	text := applyHints(e.style.Apply(strings.TrimSuffix(buf.String(), "\n")), hints)
+	_, err = io.WriteString(e.w, text+"\n")
+	return err
+}
@@ -0,0 +1,48 @@
+package rex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestMarshalPacked(t *testing.T) {
+	expected := `{
+  "name": "<Test>",
+  "location": { "x": 0, "y": 0, "z": 0 },
+  "orientation": { "x": 0, "y": 0, "z": 0, "w": 0 },
+  "color": { "r": 0, "g": 0, "b": 0 },
+  "numbers": [ 1, 2, 3 ]
+}`
+	obj := simpleObject{Name: "<Test>", Numbers: []int{1, 2, 3}}
+	// MarshalPacked escapes HTML characters, as json.Marshal does
+	escaped := strings.Replace(strings.Replace(expected, "<", `\u003c`, 1), ">", `\u003e`, 1)
+	b, err := MarshalPacked(obj, PackedStyle)
+	if text := string(b); err != nil || text != escaped {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(escaped)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	var out bytes.Buffer
+	enc := NewEncoder(&out, PackedStyle)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil || out.String() != expected+"\n" {
+		tst.Failed(t, dbg.IAm()+" Encoder", "Expected in green, genereted in red")
+		tst.AsGreen(expected + "\n")
+		tst.AsRed(out.String())
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Encoder")
+	}
+
+	if _, err := MarshalPacked(map[string]interface{}{"bad": make(chan int)}, PackedStyle); err == nil {
+		tst.Failed(t, dbg.IAm()+" Error", "Marshalling error not returned")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Error")
+	}
+}
@@ -0,0 +1,201 @@
+package rex
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Callbacks given a Match rather than the positional x []string.
+
+	Match: TYPE
+		A match of a regexp, giving its groups by name as well as the x[1] / x[2] / x[3]
+		roles of the cleanup functions.  Offsets are byte offsets into Src, the whole text
+		handed to the function doing the matching, not just the remaining text searched.
+
+	Match.Group:
+		Returns the text of the named group, "" if the group is unknown or did not match
+	Match.Sub:
+		Returns the text of the group by number, 0 being the whole match
+	Match.Span:
+		Returns the start and end offsets of the named group, -1, -1 if unknown or unmatched
+	Match.SubSpan:
+		Returns the start and end offsets of the group by number, as Span
+	Match.Lead, Match.Body, Match.Tail:
+		The text of the groups named lead, body and tail, or groups 1, 2 and 3 if the regexp
+		has no group with the name
+
+	MatchFunc: TYPE
+		Callback given each Match, returning the text to use for it
+
+	The tail group is the group named tail, or x[3] of a regexp with just the three groups
+	of the cleanup functions ending with a .* capturing the rest of the text, as in:
+		`(?s)(.*?\b)?([a-z]+)(\b.*)`
+	Any other regexp has no tail group, so its x[3] is only a group like any other.
+
+	RexReplaceMatch:
+		Like RexReplaceEach: the match up to the Tail is replaced by the MatchFunc's result,
+		and the Tail is searched again.  Without a tail group the whole match is replaced,
+		and the text following it is searched again.  Text before the match is kept.  As with
+		regexp.ReplaceAll, an empty match adjacent to the previous match is skipped.
+	RexCleanupMatch:
+		Like RexCleanup: the Lead is kept, the Body is replaced by the MatchFunc's result,
+		and the Tail is searched again
+	RexGatherMatch:
+		Calls the function for each match, searching again from the start of the Tail, or
+		after the match if the regexp has no tail group, skipping empty matches as
+		RexReplaceMatch
+*/
+
+type Match struct {
+	Src   string
+	rx    *regexp.Regexp
+	index []int // absolute offsets into Src of each group, -1 if unmatched
+	tail  int   // number of the tail group, -1 if none
+}
+
+type MatchFunc func(*Match) string
+
+// returns the Match found at (or after) offset at of src, nil if none
+func findMatch(src string, at int, tr tailRex, tail int) *Match {
+	x := findSubmatchIndex(tr, src[at:])
+	if x == nil {
+		return nil
+	}
+	for i := range x {
+		if x[i] >= 0 {
+			x[i] += at
+		}
+	}
+	return &Match{Src: src, rx: tr.rx, index: x, tail: tail}
+}
+
+func (m *Match) Sub(n int) string {
+	if n < 0 || 2*n >= len(m.index) || m.index[2*n] < 0 {
+		return ""
+	}
+	return m.Src[m.index[2*n]:m.index[2*n+1]]
+}
+
+func (m *Match) Group(name string) string {
+	return m.Sub(m.groupNumber(name, -1))
+}
+
+func (m *Match) Span(name string) (int, int) {
+	return m.SubSpan(m.groupNumber(name, -1))
+}
+
+func (m *Match) SubSpan(n int) (int, int) {
+	if n < 0 || 2*n >= len(m.index) || m.index[2*n] < 0 {
+		return -1, -1
+	}
+	return m.index[2*n], m.index[2*n+1]
+}
+
+func (m *Match) Lead() string {
+	return m.Sub(m.groupNumber("lead", 1))
+}
+
+func (m *Match) Body() string {
+	return m.Sub(m.groupNumber("body", 2))
+}
+
+func (m *Match) Tail() string {
+	return m.Sub(m.groupNumber("tail", 3))
+}
+
+// number of the named group, or def if there is no group with the name
+func (m *Match) groupNumber(name string, def int) int {
+	if n := m.rx.SubexpIndex(name); n >= 0 {
+		return n
+	}
+	return def
+}
+
+// offset following the part of the match not searched again: the start of the tail, or the
+// end of the match if there is none
+func (m *Match) end() int {
+	if m.tail >= 0 && m.index[2*m.tail] >= 0 {
+		return m.index[2*m.tail]
+	}
+	return m.index[1]
+}
+
+// number of the tail group of rx, -1 if it has none
+func tailGroup(rx *regexp.Regexp) int {
+	if n := rx.SubexpIndex("tail"); n >= 0 {
+		return n
+	}
+	if rx.NumSubexp() != 3 {
+		return -1
+	}
+	re, err := syntax.Parse(rx.String(), syntax.Perl)
+	if err != nil {
+		return -1
+	}
+	for re.Op == syntax.OpConcat {
+		re = re.Sub[len(re.Sub)-1]
+	}
+	if re.Op != syntax.OpCapture || re.Cap != 3 {
+		return -1
+	}
+	for re = re.Sub[0]; re.Op == syntax.OpConcat || re.Op == syntax.OpCapture; {
+		re = re.Sub[len(re.Sub)-1]
+	}
+	if re.Op != syntax.OpStar || re.Sub[0].Op != syntax.OpAnyChar && re.Sub[0].Op != syntax.OpAnyCharNotNL {
+		return -1
+	}
+	return 3
+}
+
+// calls the function for each match in order, as regexp.FindAll does skipping any empty
+// match adjacent to the previous one, and searching again from the start of the tail
+func eachMatch(src string, rx *regexp.Regexp, f func(*Match)) {
+	tail := tailGroup(rx)
+	if tail < 0 {
+		for _, x := range rx.FindAllStringSubmatchIndex(src, -1) {
+			f(&Match{Src: src, rx: rx, index: x, tail: tail})
+		}
+		return
+	}
+	tr := newTailRex(rx)
+	prev := -1 // end of the previous match
+	for at := 0; at <= len(src); {
+		m := findMatch(src, at, tr, tail)
+		if m == nil {
+			return
+		}
+		if end := m.end(); end != prev {
+			f(m)
+			prev, at = end, end
+		} else if _, n := utf8.DecodeRuneInString(src[at:]); n > 0 {
+			at += n // empty, and adjacent to the previous match
+		} else {
+			return
+		}
+	}
+}
+
+func RexReplaceMatch(src string, rx *regexp.Regexp, mf MatchFunc) string {
+	var result strings.Builder
+	at := 0
+	eachMatch(src, rx, func(m *Match) {
+		result.WriteString(src[at:m.index[0]])
+		result.WriteString(mf(m))
+		at = m.end()
+	})
+	result.WriteString(src[at:])
+	return result.String()
+}
+
+func RexCleanupMatch(src string, rx *regexp.Regexp, mf MatchFunc) string {
+	return RexReplaceMatch(src, rx, func(m *Match) string {
+		return m.Lead() + mf(m)
+	})
+}
+
+func RexGatherMatch(src string, rx *regexp.Regexp, gf func(*Match)) {
+	eachMatch(src, rx, gf)
+}
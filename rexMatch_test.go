@@ -0,0 +1,107 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestMatch(t *testing.T) {
+	// a group added to the pattern does not upset callbacks using the names
+	silencesRex := regexp.MustCompile(`(?P<lead>(?s).*?"(silences|gaps)": \[)\n(?P<body>(?s).*?)(?P<tail>(?s)\n +\].*)`)
+	expected := RexCleanup(complexSource, regexp.MustCompile(`((?s).*?"silences": \[)\n((?s).*?)((?s)\n +\].*)`), PackLines)
+	var found []string
+	text := RexCleanupMatch(complexSource, silencesRex, func(m *Match) string {
+		stt, end := m.Span("body")
+		found = append(found, fmt.Sprint(m.Sub(2), " ", strings.Count(m.Src[:stt], "\n")+1, " ", m.Src[end-1:end]))
+		return PackLines(m.Body())
+	})
+	if text != expected || strings.Join(found, ",") != "silences 66 }" {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+		tst.AsRed(strings.Join(found, ","))
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// without a tail group, the text after each match is searched again
+	expected = "<apple>   <banana>   <cherry>"
+	text = RexReplaceMatch("apple   banana   cherry", regexp.MustCompile(`(?P<word>[a-z]+)`), func(m *Match) string {
+		return "<" + m.Group("word") + ">"
+	})
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Replace", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Replace")
+	}
+
+	// positional groups, with offsets into the whole text
+	expected = "apple@0 banana@8 cherry@17 date@24 fig@29 grape@33"
+	found = nil
+	RexGatherMatch(wordtext[:38], lcwordRex, func(m *Match) {
+		if stt, _ := m.Span("missing"); stt == -1 && m.Group("missing") == "" {
+			stt, _ = m.SubSpan(2)
+			found = append(found, fmt.Sprintf("%s@%d", m.Body(), stt))
+		}
+	})
+	if text = strings.Join(found, " "); text != expected {
+		tst.Failed(t, dbg.IAm()+" Gather", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Gather")
+	}
+
+	// empty matches move on
+	expected = "-a-b-"
+	text = RexReplaceMatch("ab", regexp.MustCompile(`x*`), func(m *Match) string { return "-" })
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Empty", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Empty")
+	}
+
+	// an empty match following a match is skipped, as by regexp.ReplaceAllString
+	for _, c := range []struct{ src, rex string }{{"abc", `x*$`}, {"abc", `c?$`}, {"a b", `\b`}, {"ab", `x*`}} {
+		rx := regexp.MustCompile(c.rex)
+		expected = rx.ReplaceAllString(c.src, "-")
+		text = RexReplaceMatch(c.src, rx, func(m *Match) string { return "-" })
+		if text != expected {
+			tst.Failed(t, dbg.IAm()+" Adjacent "+c.rex, "Expected in green, genereted in red")
+			tst.AsGreen(expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" Adjacent "+c.rex)
+		}
+	}
+
+	// a x[3] that is not a tail is a group like any other
+	expected = "<1-2-3> <4-5-6>"
+	text = RexReplaceMatch("1-2-3 4-5-6", regexp.MustCompile(`(\d+)-(\d+)-(\d+)`), func(m *Match) string { return "<" + m.Sub(0) + ">" })
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Groups", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Groups")
+	}
+
+	expected = "[123456]"
+	text = fmt.Sprint(GatherAll("123456", regexp.MustCompile(`(\d)(\d)(\d+)`), 0))
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Groups Gather", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Groups Gather")
+	}
+}
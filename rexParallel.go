@@ -0,0 +1,160 @@
+package rex
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync"
+)
+
+/*
+	Gathering from large inputs with several goroutines.
+
+	The input is read in chunks that end at a safe boundary, a newline unless a Split
+	regexp is given, and each chunk is gathered on its own by a pool of workers.  The
+	results are merged in the order of the input, so they are the same as gathering from
+	the whole text as long as no match depends on text across a boundary.  Beware of
+	optional parts, a lead of (.*?\b)? lets [a-z]+ match the end of a capitalized word when
+	no other word follows it in the chunk.
+
+	ParallelOptions: TYPE
+		Controls GatherParallelOpts
+			Workers:	Number of goroutines gathering, 1 if less than 1
+			Group:		Group to gather, the Body (group named body, or x[2]) if 0
+			Split:		Regexp whose matches end the chunks, a newline if nil -- each search for
+						one starts a ChunkSize before the text read since the last search, so
+						a match must be shorter than the ChunkSize
+			ChunkSize:	Size a chunk is read up to before looking for a boundary, 1MB if 0
+			Unique:		Only keep the first of any repeated texts
+
+	GatherParallel:
+		Returns the Body of every match in the text read from r, in order, using the given
+		number of workers
+	GatherParallelOpts:
+		Like GatherParallel, controlled by the ParallelOptions
+*/
+
+type ParallelOptions struct {
+	Workers   int
+	Group     int
+	Split     *regexp.Regexp
+	ChunkSize int
+	Unique    bool
+}
+
+func GatherParallel(r io.Reader, rx *regexp.Regexp, workers int) ([]string, error) {
+	return GatherParallelOpts(r, rx, ParallelOptions{Workers: workers})
+}
+
+func GatherParallelOpts(r io.Reader, rx *regexp.Regexp, opts ParallelOptions) ([]string, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 1 << 20
+	}
+	type chunk struct {
+		n    int
+		text string
+	}
+	var (
+		results [][]string
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		chunks  = make(chan chunk, opts.Workers)
+	)
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				var found []string
+				RexGatherMatch(c.text, rx, func(m *Match) {
+					if opts.Group > 0 {
+						found = append(found, m.Sub(opts.Group))
+					} else {
+						found = append(found, m.Body())
+					}
+				})
+				mu.Lock()
+				results[c.n] = found
+				mu.Unlock()
+			}
+		}()
+	}
+	err := readChunks(r, opts, func(text string) {
+		mu.Lock()
+		n := len(results)
+		results = append(results, nil)
+		mu.Unlock()
+		chunks <- chunk{n, text}
+	})
+	close(chunks)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	var gathered []string
+	seen := map[string]bool{}
+	for _, found := range results {
+		for _, g := range found {
+			if opts.Unique {
+				if seen[g] {
+					continue
+				}
+				seen[g] = true
+			}
+			gathered = append(gathered, g)
+		}
+	}
+	return gathered, nil
+}
+
+// reads r, handing each chunk ending at a boundary (or the end of r) to the function
+func readChunks(r io.Reader, opts ParallelOptions, chunk func(string)) error {
+	var buf []byte
+	from := 0 // buf has been searched for a boundary up to a ChunkSize past from
+	read := make([]byte, opts.ChunkSize)
+	for {
+		n, err := io.ReadFull(r, read)
+		buf = append(buf, read[:n]...)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if len(buf) > 0 {
+				chunk(string(buf))
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if end := lastBoundary(buf, from, opts.Split); end > 0 {
+			chunk(string(buf[:end]))
+			buf = append(buf[:0:0], buf[end:]...)
+			from = 0
+		} else if from = len(buf) - opts.ChunkSize; from < 0 {
+			from = 0
+		}
+	}
+}
+
+// offset following the last boundary in buf at or after offset from, 0 if none
+func lastBoundary(buf []byte, from int, split *regexp.Regexp) int {
+	if split == nil {
+		if i := bytes.LastIndexByte(buf[from:], '\n'); i >= 0 {
+			return from + i + 1
+		}
+		return 0
+	}
+	end := 0
+	for at := from; at <= len(buf); {
+		x := split.FindIndex(buf[at:])
+		if x == nil {
+			break
+		}
+		end = at + x[1]
+		if at += x[1]; x[0] == x[1] {
+			at += 1 // empty match, move on
+		}
+	}
+	return end
+}
@@ -0,0 +1,61 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestGatherParallel(t *testing.T) {
+	var lines strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&lines, "line %d: %s", i, wordtext)
+	}
+	src := lines.String()
+	// unlike lcwordRex, the \b before a word is not optional, so no match depends on text
+	// beyond the end of a line, as "pple" would at the end of "Apple Banana Cherry\n"
+	wordRex := regexp.MustCompile(`(?s)(.*?)\b([a-z]+)\b(.*)`)
+
+	var expected []string
+	RexGather(src, wordRex, func(x []string, rx *regexp.Regexp, gf GatherFunc) {
+		expected = append(expected, x[2])
+		RexGather(x[3], rx, gf)
+	})
+
+	for _, opts := range []ParallelOptions{
+		{Workers: 1},
+		{Workers: 4, ChunkSize: 100},
+		{Workers: 8, ChunkSize: 1000, Split: regexp.MustCompile(`line \d+: `)},
+		{Workers: 2, ChunkSize: 16}, // several reads to a boundary
+		{Workers: 2, ChunkSize: 16, Split: regexp.MustCompile(`line \d+: `)}, // and matches across reads
+	} {
+		name := fmt.Sprintf(" %d/%d/%v", opts.Workers, opts.ChunkSize, opts.Split != nil)
+		found, err := GatherParallelOpts(strings.NewReader(src), wordRex, opts)
+		if text := strings.Join(found, " "); err != nil || text != strings.Join(expected, " ") {
+			tst.Failed(t, dbg.IAm()+name, "Parallel gather differs from RexGather")
+		} else {
+			tst.Passed(t, "", dbg.IAm()+name)
+		}
+	}
+
+	found, err := GatherParallel(strings.NewReader(src), wordRex, 4)
+	if err != nil || len(found) != len(expected) {
+		tst.Failed(t, dbg.IAm()+" Default", "Parallel gather differs from RexGather")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Default")
+	}
+
+	unique := "line apple banana cherry date fig grape berry mellon orange"
+	found, err = GatherParallelOpts(strings.NewReader(src), wordRex, ParallelOptions{Workers: 3, ChunkSize: 64, Unique: true})
+	if text := strings.Join(found, " "); err != nil || text != unique {
+		tst.Failed(t, dbg.IAm()+" Unique", "Expected in green, genereted in red")
+		tst.AsGreen(unique)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Unique")
+	}
+}
@@ -0,0 +1,219 @@
+package rex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+	Choosing the objects / arrays to clean with a JSONPath or a JSON Pointer (RFC 6901),
+	rather than with regexps that depend on the indentation.
+
+	Supported JSONPath forms, starting with '$' (the outermost object / array):
+		.name  ['name']  ["name"]	Member of an object
+		[4]							Entry of an array
+		.*  [*]						Any member or entry
+		..name  ..[4]  ..*			Recursive descent, any number of levels down
+	JSON Pointers start with '/', e.g. "/tracks/4/silences", with "~1" for '/' and "~0" for
+	'~' in names, a number selects an array entry or a member with that name.
+
+	Only objects and arrays can be selected, as only they are handed to a CleanerFunc.
+
+	JSONPath: TYPE
+		A compiled JSONPath or JSON Pointer
+
+	CompileJSONPath:
+		Compiles a JSONPath or JSON Pointer, returning an error if it can't be parsed
+	MustCompileJSONPath:
+		Like CompileJSONPath, but panics if the path can't be parsed
+
+	JSONPath.String:
+		Returns the path as given to CompileJSONPath
+
+	JSONPath.Select:
+		Returns a Selector for the objects / arrays in src (only) that the path selects
+
+	RexJSONCleanupPath:
+		Like JSONCleanup, using the objects / arrays selected by the path.  Panics if the
+		path can't be parsed.
+	RexJSONCleanupPathPost:
+		Like RexJSONCleanupPath, but has an additional CleanerFunc to do any post cleanup
+*/
+
+type JSONPath struct {
+	path  string
+	steps []pathStep
+}
+
+// one level of a path
+type pathStep struct {
+	key     string
+	byKey   bool // key has to match the member name
+	index   int  // array index to match, -1 for none
+	wild    bool
+	descend bool // may skip any number of levels before matching
+}
+
+func CompileJSONPath(path string) (*JSONPath, error) {
+	var (
+		steps []pathStep
+		err   error
+	)
+	switch {
+	case path == "" || path[0] == '/':
+		steps, err = parsePointer(path)
+	case path[0] == '$':
+		steps, err = parseJSONPath(path)
+	default:
+		err = fmt.Errorf("must start with '$' or '/'")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rex: invalid JSON path %q: %v", path, err)
+	}
+	return &JSONPath{path: path, steps: steps}, nil
+}
+
+func MustCompileJSONPath(path string) *JSONPath {
+	p, err := CompileJSONPath(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *JSONPath) String() string {
+	return p.path
+}
+
+func (p *JSONPath) Select(src string) Selector {
+	found := map[int]bool{}
+	cs := ScanJSON(src)
+	for i := range cs {
+		var chain []Container // from below the outermost container down to cs[i]
+		for j := i; cs[j].Parent >= 0; j = cs[j].Parent {
+			chain = append([]Container{cs[j]}, chain...)
+		}
+		if matchSteps(p.steps, chain) {
+			found[cs[i].Open] = true
+		}
+	}
+	return func(c Container) bool {
+		return found[c.Open]
+	}
+}
+
+func RexJSONCleanupPath(src, path string, cf CleanerFunc) string {
+	return RexJSONCleanupPathPost(src, path, cf, nil)
+}
+
+func RexJSONCleanupPathPost(src, path string, cf CleanerFunc, post CleanerFunc) string {
+	return JSONCleanupPost(src, MustCompileJSONPath(path).Select(src), cf, post)
+}
+
+func matchSteps(steps []pathStep, chain []Container) bool {
+	if len(steps) == 0 {
+		return len(chain) == 0
+	}
+	if len(chain) == 0 {
+		return false
+	}
+	if steps[0].match(chain[0]) && matchSteps(steps[1:], chain[1:]) {
+		return true
+	}
+	return steps[0].descend && matchSteps(steps, chain[1:])
+}
+
+func (s pathStep) match(c Container) bool {
+	switch {
+	case s.wild:
+		return true
+	case c.Index < 0:
+		return s.byKey && s.key == c.Key
+	}
+	return s.index == c.Index
+}
+
+// "/tracks/4/silences", each number is also taken as a name
+func parsePointer(path string) ([]pathStep, error) {
+	var steps []pathStep
+	if path == "" {
+		return steps, nil
+	}
+	for _, tok := range strings.Split(path[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		s := pathStep{key: tok, byKey: true, index: -1}
+		if n, err := strconv.Atoi(tok); err == nil && n >= 0 && strconv.Itoa(n) == tok {
+			s.index = n
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+// "$.tracks[*].silences", "$..verts", "$['tracks'][4]"
+func parseJSONPath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	for i := 1; i < len(path); {
+		s := pathStep{index: -1}
+		switch {
+		case strings.HasPrefix(path[i:], ".."):
+			s.descend = true
+			i += 2
+		case path[i] == '.':
+			i += 1
+		case path[i] == '[':
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", path[i], i)
+		}
+		if i < len(path) && path[i] == '[' && i+1 < len(path) && (path[i+1] == '\'' || path[i+1] == '"') {
+			end := i + 2 // a quoted name may hold a ']', so look for the closing quote
+			for ; end < len(path) && path[end] != path[i+1]; end++ {
+				if path[end] == '\\' {
+					end += 1
+				}
+			}
+			if end+1 >= len(path) || path[end+1] != ']' {
+				return nil, fmt.Errorf("bad quoted name at %d", i)
+			}
+			s.key, s.byKey = unescapePathName(path[i+2:end]), true
+			i = end + 2
+		} else if i < len(path) && path[i] == '[' {
+			j := strings.IndexByte(path[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("missing ']' after %d", i)
+			}
+			if in := path[i+1 : i+j]; in == "*" {
+				s.wild = true
+			} else if n, err := strconv.Atoi(in); err == nil && n >= 0 {
+				s.index = n
+			} else {
+				return nil, fmt.Errorf("bad index %q at %d", in, i)
+			}
+			i += j + 1
+		} else {
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j += 1
+			}
+			if j == i {
+				return nil, fmt.Errorf("missing name at %d", i)
+			}
+			s.key, s.byKey, s.wild = path[i:j], true, path[i:j] == "*"
+			i = j
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func unescapePathName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+1 < len(name) {
+			i += 1
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
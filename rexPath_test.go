@@ -0,0 +1,88 @@
+package rex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+var packedSilences = strings.Replace(complexSource, `
+      "silences": [
+        {
+          "stt": "22:13'395!32",
+          "end": "22:13'742!20"
+        },
+        {
+          "stt": "22:15'102!08",
+          "end": "22:16'4!42"
+        },
+        {
+          "stt": "23:37'217!22",
+          "end": "23:38'193!28"
+        }
+      ]`, `
+      "silences": [
+        { "stt": "22:13'395!32", "end": "22:13'742!20" },
+        { "stt": "22:15'102!08", "end": "22:16'4!42" },
+        { "stt": "23:37'217!22", "end": "23:38'193!28" }
+      ]`, 1)
+
+func TestRexJSONCleanupPath(t *testing.T) {
+	for _, path := range []string{
+		"$.tracks[*].silences[*]",
+		"$.tracks[4].silences.*",
+		"$['tracks'][*][\"silences\"][*]",
+		"$..silences[*]",
+		"$..[4].silences..[*]",
+		"/tracks/4/silences/0",
+	} {
+		text := RexJSONCleanupPath(complexSource, path, PackLines)
+		expected := packedSilences
+		if strings.HasSuffix(path, "0") {
+			// only the first silence
+			expected = strings.Replace(strings.Replace(packedSilences, `{ "stt": "22:15'102!08", "end": "22:16'4!42" }`, `{
+          "stt": "22:15'102!08",
+          "end": "22:16'4!42"
+        }`, 1), `{ "stt": "23:37'217!22", "end": "23:38'193!28" }`, `{
+          "stt": "23:37'217!22",
+          "end": "23:38'193!28"
+        }`, 1)
+		}
+		if text != expected {
+			tst.Failed(t, dbg.IAm()+" "+path, "Expected in green, genereted in red")
+			tst.AsGreen(expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+path)
+		}
+	}
+
+	// the body of the selected array, as RexJSONCleanup would give it
+	text := RexJSONCleanupPath(complexSource, "/tracks/4/silences", silencesCleaner)
+	if text != packedSilences {
+		tst.Failed(t, dbg.IAm()+" Pointer", "Expected in green, genereted in red")
+		tst.AsGreen(packedSilences)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Pointer")
+	}
+}
+
+func TestCompileJSONPath(t *testing.T) {
+	for _, path := range []string{"tracks", "$tracks", "$.tracks[", "$.tracks[x]", "$.", "$['tracks]"} {
+		if _, err := CompileJSONPath(path); err == nil {
+			tst.Failed(t, dbg.IAm()+" "+path, "No error for bad path")
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+path)
+		}
+	}
+	for _, path := range []string{"", "/", "/a~1b/~0c", "$", "$['a.b]']..x[0]"} {
+		if _, err := CompileJSONPath(path); err != nil {
+			tst.Failed(t, dbg.IAm()+" "+path, err.Error())
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+path)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+)
+
+/*
+	Cleanup rules that can be collected and applied together.
+
+	Rule: TYPE
+		A single JSON cleanup step:
+			Rex:		Regexp used with RexJSONCleanupPost, in the x[1] / x[2] / x[3] format
+			Sel:		Selector used with JSONCleanupPost, when Rex is nil
+			Cleaner:	CleanerFunc given the captured object / array
+			Post:		Optional CleanerFunc to do any post cleanup
+
+	NewRule:
+		Compiles the pattern into a Rule's Rex, returning an error if it does not follow the
+		x[1] / x[2] / x[3] format (see ValidateRex)
+	MustNewRule:
+		Like NewRule, but panics if the pattern can't be used, as regexp.MustCompile
+
+	ValidateRex:
+		Checks a regexp has exactly the three capture groups the cleanup functions use: the
+		lead text, the text handed to the CleanerFunc and the tail text.  The groups can be
+		named, in which case they must be named lead, body and tail, as in:
+			`(?P<lead>(?s).*?"tracks": \[)\n(?P<body>(?s).*?)(?P<tail>(?s)\n  \].*)`
+
+	Rule.Validate:
+		Checks the rule has a valid Rex (or a Sel) and a Cleaner
+
+	Rule.Apply:
+		Applies the rule to the text, as RexJSONCleanupPost (or JSONCleanupPost), panicking
+		with the error from Validate if the rule has no Cleaner or neither a Rex nor a Sel
+	Rule.Cleanup:
+		Applies a Rex rule to the text as RexCleanup, without any JSON padding handling, with
+		any Post applied to each cleaned text, panicking if the rule has no Rex or Cleaner
+
+	ApplyRules:
+		Applies each of the rules in turn to the text
+*/
+
+type Rule struct {
+	Rex     *regexp.Regexp
+	Sel     Selector
+	Cleaner CleanerFunc
+	Post    CleanerFunc
+}
+
+var ruleGroups = []string{"", "lead", "body", "tail"}
+
+func NewRule(pattern string, cf CleanerFunc) (Rule, error) {
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rex: rule: %v", err)
+	}
+	if err := ValidateRex(rx); err != nil {
+		return Rule{}, err
+	}
+	return Rule{Rex: rx, Cleaner: cf}, nil
+}
+
+func MustNewRule(pattern string, cf CleanerFunc) Rule {
+	r, err := NewRule(pattern, cf)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func ValidateRex(rx *regexp.Regexp) error {
+	if err := missingGroups(rx); err != nil {
+		return err
+	} else if n := rx.NumSubexp(); n > 3 {
+		return fmt.Errorf("rex: regexp `%s` has %d capture groups, needs 3: lead, body and tail -- use (?:...) for other groups", rx, n)
+	}
+	for i, name := range rx.SubexpNames() {
+		if name != "" && name != ruleGroups[i] {
+			return fmt.Errorf("rex: regexp `%s` capture group %d is named %q, needs to be %q", rx, i, name, ruleGroups[i])
+		}
+	}
+	return nil
+}
+
+func (r Rule) Validate() error {
+	switch {
+	case r.Cleaner == nil:
+		return fmt.Errorf("rex: rule has no Cleaner")
+	case r.Rex != nil:
+		return ValidateRex(r.Rex)
+	case r.Sel == nil:
+		return fmt.Errorf("rex: rule has neither a Rex nor a Sel")
+	}
+	return nil
+}
+
+func (r Rule) Apply(src string) string {
+	if r.Cleaner == nil || r.Rex == nil && r.Sel == nil {
+		panic(r.Validate())
+	}
+	if r.Rex != nil {
+		return RexJSONCleanupPost(src, r.Rex, r.Cleaner, r.Post)
+	}
+	return JSONCleanupPost(src, r.Sel, r.Cleaner, r.Post)
+}
+
+func (r Rule) Cleanup(src string) string {
+	if r.Rex == nil {
+		panic(fmt.Errorf("rex: rule has no Rex, needed by Cleanup"))
+	} else if r.Cleaner == nil {
+		panic(r.Validate())
+	}
+	if r.Post == nil {
+		return RexCleanup(src, r.Rex, r.Cleaner)
+	}
+	return RexCleanup(src, r.Rex, func(sub string) string {
+		return r.Post(r.Cleaner(sub))
+	})
+}
+
+func ApplyRules(src string, rules ...Rule) string {
+	for _, r := range rules {
+		src = r.Apply(src)
+	}
+	return src
+}
@@ -0,0 +1,100 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestNewRule(t *testing.T) {
+	for _, c := range []struct{ pattern, expected string }{
+		{`((?s).*?"tracks": \[)\n((?s).*?)((?s)\n  \].*)`, ``},
+		{`(?P<lead>(?s).*?"tracks": \[)\n(?P<body>(?s).*?)(?P<tail>(?s)\n  \].*)`, ``},
+		{`(?P<lead>(?s).*?"tracks": \[)\n((?s).*?)((?s)\n  \].*)`, ``},
+		{`((?s).*?"tracks": \[)\n((?s).*?)`,
+			"rex: regexp `((?s).*?\"tracks\": \\[)\\n((?s).*?)` has 2 capture groups, needs 3: lead, body and tail"},
+		{`((?s).*?"(tracks|discs)": \[)\n((?s).*?)((?s)\n  \].*)`,
+			"rex: regexp `((?s).*?\"(tracks|discs)\": \\[)\\n((?s).*?)((?s)\\n  \\].*)` has 4 capture groups, needs 3: lead, body and tail -- use (?:...) for other groups"},
+		{`((?s).*?)(?P<tail>(?s).*?)((?s).*)`,
+			"rex: regexp `((?s).*?)(?P<tail>(?s).*?)((?s).*)` capture group 2 is named \"tail\", needs to be \"body\""},
+		{`((?s).*?"tracks": \[)\n((?s).*?)((?s)\n  \].*`,
+			"rex: rule: error parsing regexp: missing closing ): `((?s).*?\"tracks\": \\[)\\n((?s).*?)((?s)\\n  \\].*`"},
+	} {
+		_, err := NewRule(c.pattern, PackLines)
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.pattern, "Expected in green, genereted in red")
+			tst.AsGreen(c.expected)
+			tst.AsRed(msg)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.pattern)
+		}
+	}
+
+	if err := (Rule{Sel: SelectNamed('[')}).Validate(); err == nil {
+		tst.Failed(t, dbg.IAm()+" Validate", "Rule without a Cleaner accepted")
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Validate")
+	}
+
+	// the cleanup functions report a bad regexp rather than failing with an index out of range
+	msg := func() (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		RexJSONCleanup(complexSource, regexp.MustCompile(`((?s).*?"tracks": \[)\n((?s).*)`), PackLines)
+		return
+	}()
+	expected := "rex: regexp `((?s).*?\"tracks\": \\[)\\n((?s).*)` has 2 capture groups, needs 3: lead, body and tail"
+	if msg != expected {
+		tst.Failed(t, dbg.IAm()+" Panic", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(msg)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Panic")
+	}
+}
+
+func TestRuleCleanup(t *testing.T) {
+	rule := MustNewRule(`(?P<lead>(?s).*?"silences": \[)(?P<body>(?s).*?)(?P<tail>(?s)\n +\].*)`, PackLines)
+	rule.Post = func(src string) string { return src + " " }
+	expected := RexCleanup(complexSource, rule.Rex, func(src string) string { return PackLines(src) + " " })
+	if text := rule.Cleanup(complexSource); text != expected || text == complexSource {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestRuleIncomplete(t *testing.T) {
+	// a zero Rule, or Cleanup of a Sel rule, panics saying what is missing
+	for _, c := range []struct {
+		name     string
+		apply    func()
+		expected string
+	}{
+		{"Zero", func() { Rule{}.Apply(complexSource) }, "rex: rule has no Cleaner"},
+		{"No Rex or Sel", func() { Rule{Cleaner: PackLines}.Apply(complexSource) }, "rex: rule has neither a Rex nor a Sel"},
+		{"Zero Cleanup", func() { Rule{}.Cleanup(complexSource) }, "rex: rule has no Rex, needed by Cleanup"},
+		{"Sel Cleanup", func() { Rule{Sel: NamedJSONArraySel, Cleaner: PackLines}.Cleanup(complexSource) }, "rex: rule has no Rex, needed by Cleanup"},
+	} {
+		msg := func() (msg string) {
+			defer func() { msg = fmt.Sprint(recover()) }()
+			c.apply()
+			return
+		}()
+		if msg != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.name, "Expected in green, genereted in red")
+			tst.AsGreen(c.expected)
+			tst.AsRed(msg)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.name)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package rex
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+/*
+	Token aware versions of the JSON cleanup functions.
+
+	The regexp driven RexJSONCleanup has to find the closing } or ] of a captured object /
+	array using the indentation, so patterns like `\n {6}"<NAME>": {` depend on the indent
+	width used by json.MarshalIndent.  The functions here locate objects and arrays with a
+	small JSON scanner instead, so the indent width and any brackets or braces inside of
+	string values do not matter.  The scanner is tolerant of fragments, so the text handed
+	to a CleanerFunc (the body of an object or array) can be scanned again.
+
+	Container: TYPE
+		Location of an object or array found by ScanJSON
+
+	Selector: TYPE
+		Function that reports if a Container should be handed to the CleanerFunc
+
+	ScanJSON:
+		Returns all objects and arrays in the text in order of their opening { or [
+
+	JSONCleanup:
+		Like RexJSONCleanup, but uses a Selector to choose the objects / arrays to process.
+		The CleanerFunc is given the same text it would get from RexJSONCleanup when used
+		with the NamedJSONObjectRex style regexps: the body of the object or array, without
+		the newline following the opening { or [, with the leading spaces removed.  If the
+		cleaned text ends with a newline the closing } or ] keeps its indentation, otherwise
+		it follows directly after the cleaned text.  Containers inside a processed container
+		are left for the CleanerFunc.
+
+	JSONCleanupPost:
+		Like JSONCleanup, but has an additional CleanerFunc to do any post cleanup
+
+	SelectNamed:
+		Selector for named objects ('{') or arrays ('['), optionally limited to the given names
+	SelectUnnamed:
+		Selector for unnamed objects ('{') or arrays ('[')
+	SelectDepth:
+		Limits a Selector to containers at the given depth, 0 being the outermost
+
+	Selectors to match the behavior of the JSON regexps, they only select at depth 0:
+
+	NamedJSONObjectSel: VAR
+		Selects simple "named": {object}
+	UnnamedJSONObjectSel: VAR
+		Selects simple unnamed {object}
+	NamedJSONArraySel: VAR
+		Selects simple "named": [array]
+	UnnamedJSONArraySel: VAR
+		Selects simple unnamed [array]
+*/
+
+type Container struct {
+	Key    string // name of the object / array, "" if unnamed
+	Kind   byte   // '{' or '['
+	Depth  int    // nesting depth, 0 for the outermost containers in the text
+	Index  int    // number of sibling entries before an unnamed container, -1 if named
+	Parent int    // index of the enclosing container in the scanned list, -1 at depth 0
+	Start  int    // offset of the name's opening quote, same as Open if unnamed
+	Open   int    // offset of the opening { or [
+	Close  int    // offset of the matching } or ], -1 if never closed
+}
+
+type Selector func(Container) bool
+
+var (
+	NamedJSONObjectSel   = SelectDepth(0, SelectNamed('{'))
+	NamedJSONArraySel    = SelectDepth(0, SelectNamed('['))
+	UnnamedJSONObjectSel = SelectDepth(0, SelectUnnamed('{'))
+	UnnamedJSONArraySel  = SelectDepth(0, SelectUnnamed('['))
+)
+
+func SelectNamed(kind byte, names ...string) Selector {
+	return func(c Container) bool {
+		if c.Kind != kind || c.Key == "" {
+			return false
+		}
+		if len(names) == 0 {
+			return true
+		}
+		for _, n := range names {
+			if n == c.Key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func SelectUnnamed(kind byte) Selector {
+	return func(c Container) bool {
+		return c.Kind == kind && c.Key == ""
+	}
+}
+
+func SelectDepth(depth int, sel Selector) Selector {
+	return func(c Container) bool {
+		return c.Depth == depth && sel(c)
+	}
+}
+
+func ScanJSON(src string) []Container {
+	var (
+		found  []Container
+		stack  []int // indexes into found of the open containers
+		commas = []int{0}
+		keyStt = -1 // start of the string before the last ':'
+		strStt = -1 // start of the last string seen
+		key    string
+	)
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '"':
+			j := skipJSONString(src, i)
+			strStt = i
+			i = j - 1
+		case ':':
+			if strStt >= 0 {
+				keyStt, key = strStt, jsonUnquote(src[strStt:i])
+			}
+		case ',':
+			commas[len(commas)-1] += 1
+			keyStt, strStt, key = -1, -1, ""
+		case '{', '[':
+			n := Container{Kind: c, Depth: len(stack), Index: -1, Parent: -1, Start: i, Open: i, Close: -1}
+			if keyStt >= 0 {
+				n.Key, n.Start = key, keyStt
+			} else {
+				n.Index = commas[len(commas)-1]
+			}
+			if len(stack) > 0 {
+				n.Parent = stack[len(stack)-1]
+			}
+			stack = append(stack, len(found))
+			commas = append(commas, 0)
+			found = append(found, n)
+			keyStt, strStt, key = -1, -1, ""
+		case '}', ']':
+			if len(stack) > 0 {
+				found[stack[len(stack)-1]].Close = i
+				stack = stack[:len(stack)-1]
+				commas = commas[:len(commas)-1]
+			}
+			keyStt, strStt, key = -1, -1, ""
+		case ' ', '\t', '\r', '\n':
+		default:
+			strStt = -1
+		}
+	}
+	return found
+}
+
+// returns the offset following the closing quote of the string starting at src[i]
+func skipJSONString(src string, i int) int {
+	for i += 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i += 1
+		case '"':
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// src includes the quotes, any bad string is returned without them
+func jsonUnquote(src string) string {
+	var s string
+	if err := json.Unmarshal([]byte(src), &s); err != nil {
+		return strings.Trim(src, `"`)
+	}
+	return s
+}
+
+func JSONCleanup(src string, sel Selector, cf CleanerFunc) string {
+	return JSONCleanupPost(src, sel, cf, nil)
+}
+
+func JSONCleanupPost(src string, sel Selector, cf CleanerFunc, post CleanerFunc) string {
+	var result strings.Builder
+	done := 0 // src has been copied up to here
+	for _, c := range ScanJSON(src) {
+		if c.Close < 0 || c.Open < done || !sel(c) {
+			continue
+		}
+		stt := c.Open + 1
+		if stt >= c.Close || src[stt] != '\n' {
+			continue // already packed, or empty
+		}
+		stt += 1
+		end, closeLead := c.Close, ""
+		if l := strings.LastIndex(src[:c.Close], "\n") + 1; l >= stt && strings.TrimLeft(src[l:c.Close], " \t") == "" {
+			end, closeLead = l, src[l:c.Close]
+		}
+		lead, sub := RemoveJSONPadding(src[stt:end])
+		text := AddJSONPadding(lead, cf(sub))
+		if post != nil {
+			text = post(text)
+		}
+		if strings.HasSuffix(text, "\n") {
+			text += closeLead
+		}
+		result.WriteString(src[done : c.Open+1])
+		result.WriteString(text)
+		done = c.Close
+	}
+	result.WriteString(src[done:])
+	return result.String()
+}
+
+// one entry of an object or array body, as split by jsonEntries
+type jsonEntry struct {
+	text string // entry with surrounding white space removed, including any trailing ','
+	at   int    // offset of text in the split source
+	open int    // offset in text of a multi-line container's { or [, -1 for other entries
+	shut int    // offset in text of the container's closing } or ]
+}
+
+// splits the body of an object / array (or a whole document) into its top level entries
+func jsonEntries(src string) []jsonEntry {
+	var (
+		entries []jsonEntry
+		depth   int
+		stt     int
+		open    = -1
+		shut    = -1
+	)
+	add := func(end int) {
+		text := src[stt:end]
+		trim := len(text) - len(strings.TrimLeft(text, " \t\r\n"))
+		if text = strings.TrimSpace(text); text != "" {
+			e := jsonEntry{text: text, at: stt + trim, open: -1, shut: -1}
+			if open >= 0 && shut > open && strings.Contains(src[open:shut], "\n") {
+				e.open, e.shut = open-stt-trim, shut-stt-trim
+			}
+			entries = append(entries, e)
+		}
+		stt, open, shut = end, -1, -1
+	}
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			i = skipJSONString(src, i) - 1
+		case '{', '[':
+			if depth == 0 && open < 0 {
+				open = i
+			}
+			depth += 1
+		case '}', ']':
+			if depth -= 1; depth == 0 && shut < 0 {
+				shut = i
+			}
+		case ',':
+			if depth == 0 {
+				add(i + 1)
+			}
+		}
+	}
+	add(len(src))
+	return entries
+}
+
+// parsed form of JSON text, used where the original line breaks do not matter
+type jsonNode struct {
+	key   string // member name including its quotes, "" for array entries
+	value string // text of a simple value, "" for objects and arrays
+	kind  byte   // '{' or '[' for objects and arrays, 0 for simple values
+	items []*jsonNode
+}
+
+// parses the sequence of values / members in src, tolerating fragments and bad JSON -- a
+// closing } or ] without an opening one is skipped
+func parseJSON(src string) []*jsonNode {
+	var nodes []*jsonNode
+	for i := 0; i < len(src); i++ {
+		var items []*jsonNode
+		items, i = parseJSONItems(src, i)
+		nodes = append(nodes, items...)
+	}
+	return nodes
+}
+
+// parses items until the end of src or an unmatched } or ], returning the offset reached
+func parseJSONItems(src string, i int) ([]*jsonNode, int) {
+	var nodes []*jsonNode
+	for i < len(src) {
+		switch src[i] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			i += 1
+		case '}', ']':
+			return nodes, i
+		default:
+			var n *jsonNode
+			n, i = parseJSONValue(src, i)
+			if j := skipJSONSpace(src, i); n.kind == 0 && n.value[0] == '"' && j < len(src) && src[j] == ':' {
+				key := n.value
+				n, i = parseJSONValue(src, skipJSONSpace(src, j+1))
+				n.key = key
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, i
+}
+
+func parseJSONValue(src string, i int) (*jsonNode, int) {
+	if i >= len(src) {
+		return &jsonNode{value: "null"}, i
+	}
+	switch c := src[i]; c {
+	case '{', '[':
+		n := &jsonNode{kind: c}
+		n.items, i = parseJSONItems(src, i+1)
+		if i < len(src) {
+			i += 1 // skip the closing } or ]
+		}
+		return n, i
+	case '"':
+		j := skipJSONString(src, i)
+		return &jsonNode{value: src[i:j]}, j
+	}
+	j := i + 1
+	for j < len(src) && !strings.ContainsRune(",:{}[] \t\r\n", rune(src[j])) {
+		j += 1
+	}
+	return &jsonNode{value: src[i:j]}, j
+}
+
+func skipJSONSpace(src string, i int) int {
+	for i < len(src) && strings.ContainsRune(" \t\r\n", rune(src[i])) {
+		i += 1
+	}
+	return i
+}
+
+// single line form of the node, as in: "name": { "a": 1, "b": [ 1, 2 ] }
+func (n *jsonNode) flat() string {
+	var b strings.Builder
+	n.writeFlat(&b)
+	return b.String()
+}
+
+func (n *jsonNode) writeFlat(b *strings.Builder) {
+	if n.key != "" {
+		b.WriteString(n.key + ": ")
+	}
+	if n.kind == 0 {
+		b.WriteString(n.value)
+		return
+	}
+	b.WriteByte(n.kind)
+	for i, c := range n.items {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(' ')
+		c.writeFlat(b)
+	}
+	if len(n.items) > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteByte(n.closer())
+}
+
+// the opening line of an object / array, as in: "name": {
+func (n *jsonNode) head() string {
+	if n.key != "" {
+		return n.key + ": " + string(n.kind)
+	}
+	return string(n.kind)
+}
+
+func (n *jsonNode) closer() byte {
+	if n.kind == '[' {
+		return ']'
+	}
+	return '}'
+}
@@ -0,0 +1,132 @@
+package rex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestJSONCleanupTightPacking(t *testing.T) {
+	// same result as TestRexJSONTightPacking, but using selectors instead of regexps
+	expected := `{
+  "verts": [
+    { "p": { "x": 0, "y": 0, "z": 0 },
+      "c": { "r": 0, "g": 0, "b": 0 },
+      "o": { "x": 0, "y": 0, "z": 0, "w": 0 } },
+    { "p": { "x": 0, "y": 0, "z": 0 },
+      "c": { "r": 0, "g": 0, "b": 0 },
+      "o": { "x": 0, "y": 0, "z": 0, "w": 0 } },
+    { "p": { "x": 0, "y": 0, "z": 0 },
+      "c": { "r": 0, "g": 0, "b": 0 },
+      "o": { "x": 0, "y": 0, "z": 0, "w": 0 } }
+  ],
+  "subverts": {
+    "verts": [
+      { "p": { "x": 0, "y": 0, "z": 0 },
+        "c": { "r": 0, "g": 0, "b": 0 },
+        "o": { "x": 0, "y": 0, "z": 0, "w": 0 } },
+      { "p": { "x": 0, "y": 0, "z": 0 },
+        "c": { "r": 0, "g": 0, "b": 0 },
+        "o": { "x": 0, "y": 0, "z": 0, "w": 0 } },
+      { "p": { "x": 0, "y": 0, "z": 0 },
+        "c": { "r": 0, "g": 0, "b": 0 },
+        "o": { "x": 0, "y": 0, "z": 0, "w": 0 } }
+    ]
+  }
+}`
+	testObject := objects{}
+	testObject.Verticies = make([]vert, 3)
+	b, _ := json.MarshalIndent(testObject, "", "  ")
+
+	cleanVerts := func(src string) string {
+		return "\n" + JSONCleanupPost(src, UnnamedJSONObjectSel, func(s string) string {
+			return JSONCleanup(s, NamedJSONObjectSel, PackLines)
+		}, func(i string) string {
+			return " " + strings.TrimSpace(i) + " "
+		})
+	}
+	text := JSONCleanup(string(b), SelectNamed('[', "verts"), cleanVerts)
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestJSONCleanupIndentAndStrings(t *testing.T) {
+	// 4 space indent, with strings that would confuse the regexps
+	source := `{
+    "name": "\n}\n\"location\": {",
+    "location": {
+        "x": "}",
+        "y": "]",
+        "z": 0
+    },
+    "silences": [
+        {
+            "stt": "{ [",
+            "end": "] }"
+        },
+        {
+            "stt": "\"}\"",
+            "end": "x"
+        }
+    ]
+}`
+	expected := `{
+    "name": "\n}\n\"location\": {",
+    "location": { "x": "}", "y": "]", "z": 0 },
+    "silences": [
+        { "stt": "{ [", "end": "] }" },
+        { "stt": "\"}\"", "end": "x" }
+    ]
+}`
+	text := JSONCleanup(source, SelectNamed('{', "location"), PackLines)
+	text = JSONCleanup(text, SelectNamed('[', "silences"), func(s string) string {
+		return "\n" + JSONCleanup(s, UnnamedJSONObjectSel, PackLines)
+	})
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
+
+func TestJSONCleanupBadJSON(t *testing.T) {
+	// unbalanced brackets and lines indented less than the first of a body
+	cf := func(s string) string { return "\n" + JSONCleanup(s, SelectUnnamed('['), PackLines) }
+	post := func(s string) string { return " " + strings.TrimSpace(s) + " " }
+	panicked := ""
+	for _, good := range []string{mixedArrays, complexSource} {
+		for i := 0; i < len(good) && panicked == ""; i += 1 {
+			for _, bad := range []string{`"`, `{`, `}`, `[`, `]`, ""} {
+				src := good[:i] + bad + good[i:]
+				if bad == "" {
+					src = good[:i] + good[i+1:]
+				}
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							panicked = fmt.Sprintf("%q at %d: %v", bad, i, r)
+						}
+					}()
+					JSONCleanupPost(src, SelectNamed('['), cf, post)
+					JSONCleanup(src, SelectNamed('{'), cf)
+				}()
+			}
+		}
+	}
+	if panicked != "" {
+		tst.Failed(t, dbg.IAm(), "Panicked on "+panicked)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
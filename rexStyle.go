@@ -0,0 +1,53 @@
+package rex
+
+/*
+	Layout styles for marshalled JSON.
+
+	Style: TYPE
+		How JSON is to be laid out:
+			Indent:		Indentation used by json.MarshalIndent, "  " if empty
+			Steps:		CleanerFuncs applied in turn to the marshalled JSON, e.g. a Rule's Apply
+
+	Style.Apply:
+		Applies each of the Steps in turn to the JSON
+
+	IndentStyle: VAR
+		Leaves the json.MarshalIndent output as is
+	PackedStyle: VAR
+		Packs the JSON with the default AutoPack
+
+	LayoutStyle:
+		Returns a Style doing a Layout within the given width
+*/
+
+type Style struct {
+	Indent string
+	Steps  []CleanerFunc
+}
+
+var (
+	IndentStyle = Style{}
+	PackedStyle = Style{Steps: []CleanerFunc{func(src string) string {
+		return AutoPack(src, AutoPackOptions{})
+	}}}
+)
+
+func LayoutStyle(width int) Style {
+	return Style{Steps: []CleanerFunc{func(src string) string {
+		return Layout(src, LayoutOptions{Width: width})
+	}}}
+}
+
+func (s Style) Apply(src string) string {
+	for _, cf := range s.Steps {
+		src = cf(src)
+	}
+	return src
+}
+
+func (s Style) indent() string {
+	if s.Indent == "" {
+		return "  "
+	}
+	return s.Indent
+}
@@ -0,0 +1,190 @@
+package rex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+	Styles kept as data, in a small line based format:
+
+		# album layout
+		indent 2
+		align artist artist-match save-artist album save-album
+
+		path $.tracks[*]
+			align title artist min 16
+			group trk-*
+			group aud-*
+		path $..silences
+			rows
+
+	Blank lines and lines starting with '#' are ignored, the indentation of lines does not
+	matter.  Each line is a step, applied in the order given.  The lines before the first
+	path apply to the whole document:
+		indent N | tab			Indentation to marshal with, N spaces or a tab
+		autopack [width N]		AutoPack, optionally within N columns
+		layout N				Layout within N columns
+		align KEY... [min N]	AlignValues for the given keys (all when none), with MinWidth N
+	A path line starts a block of steps applied (as JSONCleanup) to the body of each object
+	or array the JSONPath / JSON Pointer selects:
+		pack					Packs the whole object / array onto one line
+		rows					Puts each entry / member on a line of its own
+		align KEY... [min N]	As above
+		group NAME...			GroupKeys with a single group of the names, a name ending in
+								'*' matching any name starting with the rest
+
+	LoadStyle:
+		Reads a Style from r, returning an error giving the line of any bad step
+*/
+
+func LoadStyle(r io.Reader) (Style, error) {
+	var (
+		style Style
+		path  *JSONPath
+		steps []CleanerFunc // of the current path block
+	)
+	endBlock := func() {
+		if path != nil {
+			style.Steps = append(style.Steps, pathStyleStep(path, steps))
+		}
+		path, steps = nil, nil
+	}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+		bad := func(msg string) (Style, error) {
+			return Style{}, fmt.Errorf("rex: style line %d: %s: %s", n, args[0], msg)
+		}
+		var (
+			step CleanerFunc
+			err  error
+		)
+		switch args[0] {
+		case "path":
+			if len(args) != 2 {
+				return bad("expected a single path")
+			}
+			endBlock()
+			if path, err = CompileJSONPath(args[1]); err != nil {
+				return bad(err.Error())
+			}
+			continue
+		case "align":
+			opts := AlignOptions{Keys: args[1:]}
+			if l := len(args); l >= 3 && args[l-2] == "min" {
+				if opts.MinWidth, err = strconv.Atoi(args[l-1]); err != nil {
+					return bad("bad min width")
+				}
+				opts.Keys = args[1 : l-2]
+			}
+			step = AlignValues(opts)
+		case "indent", "autopack", "layout":
+			if path != nil {
+				return bad("not allowed in a path block")
+			}
+			if step, err = documentStep(&style, args); err != nil {
+				return bad(err.Error())
+			}
+			if step == nil {
+				continue
+			}
+		case "pack", "rows":
+			if path == nil || len(args) != 1 {
+				return bad("only allowed, without arguments, in a path block")
+			}
+			if step = PackLines; args[0] == "rows" {
+				step = rowsCleaner
+			}
+		case "group":
+			if path == nil || len(args) < 2 {
+				return bad("only allowed, with names, in a path block")
+			}
+			var g KeyGroup
+			for _, name := range args[1:] {
+				if strings.HasSuffix(name, "*") {
+					g.Prefixes = append(g.Prefixes, name[:len(name)-1])
+				} else {
+					g.Names = append(g.Names, name)
+				}
+			}
+			step = GroupKeys(g)
+		default:
+			return bad("unknown step")
+		}
+		if path != nil {
+			steps = append(steps, step)
+		} else {
+			style.Steps = append(style.Steps, step)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Style{}, err
+	}
+	endBlock()
+	return style, nil
+}
+
+// the indent, autopack and layout steps, indent setting the style's Indent
+func documentStep(style *Style, args []string) (CleanerFunc, error) {
+	switch {
+	case args[0] == "indent" && len(args) == 2 && args[1] == "tab":
+		style.Indent = "\t"
+	case args[0] == "indent" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("expected a number of spaces or tab")
+		}
+		style.Indent = strings.Repeat(" ", n)
+	case args[0] == "autopack" && len(args) == 1:
+		return func(src string) string { return AutoPack(src, AutoPackOptions{}) }, nil
+	case args[0] == "autopack" && len(args) == 3 && args[1] == "width":
+		n, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("bad width")
+		}
+		return func(src string) string { return AutoPack(src, AutoPackOptions{MaxWidth: n}) }, nil
+	case args[0] == "layout" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad width")
+		}
+		return func(src string) string { return Layout(src, LayoutOptions{Width: n, Indent: style.Indent}) }, nil
+	default:
+		return nil, fmt.Errorf("bad arguments")
+	}
+	return nil, nil
+}
+
+func pathStyleStep(path *JSONPath, steps []CleanerFunc) CleanerFunc {
+	cf := func(src string) string {
+		for _, step := range steps {
+			src = step(src)
+		}
+		if strings.Contains(src, "\n") && !strings.HasPrefix(src, "\n") {
+			src = "\n" + src // keep the line break following the { or [
+		}
+		return src
+	}
+	return func(src string) string {
+		return JSONCleanup(src, path.Select(src), cf)
+	}
+}
+
+// puts each entry of the body on its own line
+func rowsCleaner(src string) string {
+	var result strings.Builder
+	for i, n := range parseJSON(src) {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+		result.WriteString("\n" + n.flat())
+	}
+	return result.String() + "\n"
+}
@@ -0,0 +1,207 @@
+package rex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+var albumStyle = `# album layout
+indent 2
+align artist artist-match save-artist album save-album
+
+path $.tracks[*]
+	align title artist min 16
+	group trk-*
+	group aud-*
+path $..silences
+	rows
+`
+
+func TestLoadStyle(t *testing.T) {
+	expected := `{
+  "mbid": "ABCDABCDABCDABCDABCDABCDABC-0",
+  "artist":       "FooBar & Boo",
+  "artist-match": "FooBarBoo",
+  "save-artist":  "FooBar",
+  "album":        "The Best Of: FooBar",
+  "save-album":   "Best Of FooBar",
+  "length": "32:47",
+  "mbuuid": "12345678-1234-abcd-efab-0123456789ab",
+  "freedbID": "ab123456",
+  "source": "mbrainz",
+  "trackCount": 7,
+  "tracks": [
+    {
+      "title":        "All The Way To Foobar",
+      "number": 1,
+      "trk-stt": "0:00", "trk-end": "3:42'659!48", "trk-len": "3:42.733",
+      "aud-stt": "0:00'244", "aud-end": "3:39'227!48", "aud-len": "3:38.982"
+    },
+    {
+      "title":        "Back To Foobar",
+      "artist":       "Foo & The Bars",
+      "number": 2,
+      "trk-stt": "3:42'660", "trk-end": "7:56'179!48", "trk-len": "4:13.467",
+      "aud-stt": "3:42'897", "aud-end": "7:53'444!48", "aud-len": "4:10.498"
+    },
+    {
+      "title":        "Foobar All Night Long",
+      "number": 3,
+      "trk-stt": "7:56'180", "trk-end": "12:18'23!48", "trk-len": "4:21.827",
+      "aud-stt": "7:56'451", "aud-end": "12:15'132!48", "aud-len": "4:18.647"
+    },
+    {
+      "title":        "Where The Foobar Are You?",
+      "number": 4,
+      "trk-stt": "12:18'24", "trk-end": "16:20'179!48", "trk-len": "4:02.173",
+      "aud-stt": "12:18'304", "aud-end": "16:16'309!48", "aud-len": "3:58.007"
+    },
+    {
+      "title":        "Kick The Foobar",
+      "artist":       "Foo & The Bars (f/ Boo)",
+      "number": 5,
+      "trk-stt": "16:20'180", "trk-end": "24:43'899!48", "trk-len": "8:23.800",
+      "aud-stt": "16:20'414", "aud-end": "24:39'163!48", "aud-len": "8:18.722",
+      "silences": [
+        { "stt": "22:13'395!32", "end": "22:13'742!20" },
+        { "stt": "22:15'102!08", "end": "22:16'4!42" },
+        { "stt": "23:37'217!22", "end": "23:38'193!28" }
+      ]
+    },
+    {
+      "title":        "When I Reach Foobar, I'm Happy",
+      "artist":       "FooBarBoo",
+      "number": 6,
+      "trk-stt": "24:44", "trk-end": "27:58'803!48", "trk-len": "3:14.893",
+      "aud-stt": "24:44'268", "aud-end": "27:54'791!48", "aud-len": "3:10.582"
+    },
+    {
+      "title":        "Merry Foo - Happy Bar",
+      "number": 7,
+      "trk-stt": "27:58'804", "trk-end": "32:46'899!48", "trk-len": "4:48.107",
+      "aud-stt": "27:59'164", "aud-end": "32:43'655!48", "aud-len": "4:44.547"
+    }
+  ]
+}`
+	style, err := LoadStyle(strings.NewReader(albumStyle))
+	if err != nil {
+		tst.Failed(t, dbg.IAm(), err.Error())
+		return
+	}
+	if text := style.Apply(complexSource); text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	expected = `{
+    "location": { "x": 0, "y": 0, "z": 0 },
+    "numbers": [ 1, 2, 3 ]
+}`
+	style, err = LoadStyle(strings.NewReader("indent 4\npath $.*\n\tpack\n"))
+	b, _ := MarshalPacked(struct {
+		Location pt    `json:"location"`
+		Numbers  []int `json:"numbers"`
+	}{Numbers: []int{1, 2, 3}}, style)
+	if text := string(b); err != nil || text != expected {
+		tst.Failed(t, dbg.IAm()+" Indent", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Indent")
+	}
+
+	for _, bad := range []string{
+		"rows",
+		"path $.tracks\nindent 4",
+		"path tracks",
+		"path $.tracks\ngroup",
+		"align title min x",
+		"autopack width",
+		"squash",
+	} {
+		if _, err := LoadStyle(strings.NewReader("# bad\n" + bad)); err == nil || !strings.Contains(err.Error(), "line ") {
+			tst.Failed(t, dbg.IAm()+" "+bad, "No error with the line for a bad style")
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+bad)
+		}
+	}
+}
+
+func TestLoadStyleTabs(t *testing.T) {
+	type point struct {
+		X int    `json:"x"`
+		Y int    `json:"y"`
+		N string `json:"name"`
+	}
+	v := map[string]interface{}{"points": []point{{1, 2, "a"}, {10, 20, "Beyonce"}}, "list": [][]int{{1, 2}, {3}}}
+
+	expected := "{\n\t\"list\": [\n\t\t[ 1, 2 ],\n\t\t[ 3 ]\n\t],\n\t\"points\": [\n\t\t{ \"x\": 1, \"y\": 2, \"name\": \"a\" },\n\t\t{ \"x\": 10, \"y\": 20, \"name\": \"Beyonce\" }\n\t]\n}"
+	style, err := LoadStyle(strings.NewReader("indent tab\nautopack\n"))
+	b, _ := MarshalPacked(v, style)
+	if err != nil || string(b) != expected {
+		tst.Failed(t, dbg.IAm()+" AutoPack", fmt.Sprint("Expected in green, genereted in red: ", err))
+		tst.AsGreen(expected)
+		tst.AsRed(string(b))
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" AutoPack")
+	}
+
+	expected = "{\n\t\"list\": [\n\t\t[ 1, 2 ],\n\t\t[ 3 ]\n\t],\n\t\"points\": [\n\t\t{ \"x\":  1, \"y\":  2, \"name\": \"a\"       },\n\t\t{ \"x\": 10, \"y\": 20, \"name\": \"Beyonce\" }\n\t]\n}"
+	style.Steps = append(style.Steps, AlignTable(nil))
+	if b, _ = MarshalPacked(v, style); string(b) != expected {
+		tst.Failed(t, dbg.IAm()+" Table", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(string(b))
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Table")
+	}
+
+	expected = "{\n\t\"list\": [ [ 1, 2 ], [ 3 ] ],\n\t\"points\": [\n\t\t{ \"x\": 1, \"y\": 2, \"name\": \"a\" },\n\t\t{ \"x\": 10, \"y\": 20, \"name\": \"Beyonce\" }\n\t]\n}"
+	style, err = LoadStyle(strings.NewReader("indent tab\nlayout 50\n"))
+	b, _ = MarshalPacked(v, style)
+	if err != nil || string(b) != expected {
+		tst.Failed(t, dbg.IAm()+" Layout", fmt.Sprint("Expected in green, genereted in red: ", err))
+		tst.AsGreen(expected)
+		tst.AsRed(string(b))
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Layout")
+	}
+}
+
+func TestLoadStyleBadJSON(t *testing.T) {
+	// unbalanced brackets anywhere, as in the silences around offset 1703
+	style, err := LoadStyle(strings.NewReader(albumStyle))
+	if err != nil {
+		tst.Failed(t, dbg.IAm(), err.Error())
+		return
+	}
+	panicked := ""
+	for i := 0; i < len(complexSource) && panicked == ""; i += 8 {
+		for _, bad := range []string{`"`, `{`, `}`, `[`, `]`, ""} {
+			src := complexSource[:i] + bad + complexSource[i:]
+			if bad == "" {
+				src = complexSource[i:]
+			}
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked = fmt.Sprintf("%q at %d: %v", bad, i, r)
+					}
+				}()
+				style.Apply(src)
+			}()
+		}
+	}
+	if panicked != "" {
+		tst.Failed(t, dbg.IAm(), "Panicked on "+panicked)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+}
@@ -0,0 +1,134 @@
+package rex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Table layout of arrays of simple objects.
+
+	AlignTable:
+		Returns a CleanerFunc that places each object of an array on its own row, padding
+		the members so the same key is found in the same column on every row, as in:
+			"silences": [
+			  { "stt": "22:13'395!32", "end": "22:13'742!20", "gap":  12 },
+			  { "stt": "22:15'102!08", "end": "22:16'4!42",   "gap": 108 }
+			]
+		Numbers are right aligned, all other values left aligned.  Keys missing from a row
+		are left blank.  Only arrays spread over several lines and holding nothing but
+		objects of simple values are changed, the rows can be packed already (as from
+		RexJSONCleanup) or still spread over several lines (as from json.MarshalIndent).
+		Arrays whose rows have their keys in conflicting orders are left alone.  The
+		Selector limits the arrays changed, nil allows any array.
+*/
+
+func AlignTable(sel Selector) CleanerFunc {
+	return func(src string) string {
+		var result strings.Builder
+		done := 0
+		for _, c := range ScanJSON(src) {
+			if c.Kind != '[' || c.Close < 0 || c.Open < done || (sel != nil && !sel(c)) {
+				continue
+			}
+			if !strings.Contains(src[c.Open:c.Close], "\n") {
+				continue
+			}
+			rows, ok := tableRows(parseJSON(src[c.Open+1 : c.Close]))
+			if !ok {
+				continue
+			}
+			lead, rowLead := rowIndents(src, c)
+			result.WriteString(src[done : c.Open+1])
+			for i, r := range rows {
+				if i > 0 {
+					result.WriteByte(',')
+				}
+				result.WriteString("\n" + rowLead + r)
+			}
+			result.WriteString("\n" + lead)
+			done = c.Close
+		}
+		result.WriteString(src[done:])
+		return result.String()
+	}
+}
+
+// returns the padded rows for the objects, or false if they can not be made into a table
+func tableRows(items []*jsonNode) ([]string, bool) {
+	var keys []string
+	width := map[string]int{}
+	for _, n := range items {
+		if n.kind != '{' || n.key != "" {
+			return nil, false
+		}
+		at := 0 // keys in each row must follow the order of the earlier rows
+		for _, m := range n.items {
+			if m.kind != 0 || m.key == "" {
+				return nil, false
+			}
+			i := indexOf(keys, m.key)
+			if i < 0 {
+				keys = append(keys[:at], append([]string{m.key}, keys[at:]...)...)
+				i = at
+			} else if i < at {
+				return nil, false
+			}
+			at = i + 1
+			if n := utf8.RuneCountInString(m.value); n > width[m.key] {
+				width[m.key] = n
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, false
+	}
+	rows := make([]string, len(items))
+	for r, n := range items {
+		cells := make([]string, len(keys))
+		for i, k := range keys {
+			cells[i] = strings.Repeat(" ", utf8.RuneCountInString(k)+2+width[k]+1)
+		}
+		for j, m := range n.items {
+			comma, pad := ",", strings.Repeat(" ", width[m.key]-utf8.RuneCountInString(m.value))
+			if j == len(n.items)-1 {
+				comma = " "
+			}
+			if c := m.value[0]; c == '-' || (c >= '0' && c <= '9') {
+				cells[indexOf(keys, m.key)] = m.key + ": " + pad + m.value + comma
+			} else {
+				cells[indexOf(keys, m.key)] = m.key + ": " + m.value + comma + pad
+			}
+		}
+		rows[r] = "{ " + strings.Join(cells, " ") + "}"
+	}
+	return rows, true
+}
+
+func indexOf(list []string, s string) int {
+	for i, l := range list {
+		if l == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// returns the leading spaces and tabs of the line holding src[at]
+func lineIndent(src string, at int) string {
+	l := strings.LastIndex(src[:at], "\n") + 1
+	return src[l : l+len(src[l:at])-len(strings.TrimLeft(src[l:at], " \t"))]
+}
+
+// the indent for the closing bracket of c and for its entries, placed one to a line: those
+// already used, or the indent of the line of the opening bracket and two spaces more
+func rowIndents(src string, c Container) (lead, rowLead string) {
+	lead, rowLead = lineIndent(src, c.Open), lineIndent(src, c.Open)+"  "
+	if first := skipJSONSpace(src, c.Open+1); strings.Contains(src[c.Open:first], "\n") {
+		rowLead = lineIndent(src, first)
+	}
+	if strings.TrimLeft(src[strings.LastIndex(src[:c.Close], "\n")+1:c.Close], " \t") == "" {
+		lead = lineIndent(src, c.Close)
+	}
+	return lead, rowLead
+}
@@ -0,0 +1,74 @@
+package rex
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestAlignTable(t *testing.T) {
+	source := `{
+  "title": "Kick The Foobar",
+  "silences": [
+    {
+      "stt": "22:13'395!32",
+      "end": "22:13'742!20",
+      "gap": 12
+    },
+    {
+      "stt": "22:15'102!08",
+      "end": "22:16'4!42",
+      "gap": 108
+    },
+    {
+      "stt": "23:37'217!22",
+      "end": "23:38'193!28"
+    }
+  ]
+}`
+	expected := `{
+  "title": "Kick The Foobar",
+  "silences": [
+    { "stt": "22:13'395!32", "end": "22:13'742!20", "gap":  12 },
+    { "stt": "22:15'102!08", "end": "22:16'4!42",   "gap": 108 },
+    { "stt": "23:37'217!22", "end": "23:38'193!28"             }
+  ]
+}`
+	text := AlignTable(nil)(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" MarshalIndent", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" MarshalIndent")
+	}
+
+	// the already packed version from RexJSONCleanup
+	source = RexJSONCleanup(source, NamedJSONArrayRex, silencesCleaner)
+	text = AlignTable(SelectNamed('[', "silences"))(source)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Packed", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Packed")
+	}
+
+	// widths count runes, as AlignValues does
+	expected = `[
+  { "name": "Beyoncé", "année": 2003 },
+  { "name": "Bey",     "année":   16 }
+]`
+	text = AlignTable(nil)(`[
+  { "name": "Beyoncé", "année": 2003 },
+  { "name": "Bey", "année": 16 }
+]`)
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Runes", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Runes")
+	}
+}
@@ -0,0 +1,195 @@
+package rex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+	Cleanup of typographic characters, as found in scraped text.
+
+	TypographyTable: TYPE
+		Replacement text for each rune to be replaced, "" to remove the rune
+
+	PlainTypography: VAR
+		Table replacing typographic characters with plain ASCII:
+			‘ ’ ‚ ‛ ′			'
+			“ ” „ ‟ ″			"
+			‐ ‑ ‒ – −			-		(hyphens, figure dash, en dash and minus)
+			— ―					--		(em dash and horizontal bar)
+			…					...
+			non-breaking, thin and other fixed width spaces become a plain space, zero width
+			spaces and joiners and the byte order mark are removed
+
+	The cleaners work on JSON: only the contents of string values are changed, keys and
+	everything outside the strings are left alone, and any " or \ put into a string is
+	escaped.  A string starts at a " and ends at the next " not escaped by a \, or at the end
+	of the line, so they also work on the bodies handed to a CleanerFunc.
+
+	NormalizeTypography: CleanerFunc
+		Replaces the characters in PlainTypography in the string values
+	NormalizeTypographyWith:
+		Returns a CleanerFunc replacing the characters in the given table in the string
+		values, e.g. a copy of PlainTypography with changes
+
+	SmartTypography: CleanerFunc
+		The reverse of NormalizeTypography for quotes, ellipsis and dashes in the string
+		values: a ' or \" is opening (‘ or “) at the start of the string or following white
+		space or an opening bracket or quote, otherwise it is closing (’ or ”), as is an
+		apostrophe; "..." becomes … and "--" becomes —
+
+	NormalizeTextTypography, SmartTextTypography: CleanerFunc
+		The same for plain text, where every character is changed and a " is just a quote
+*/
+
+type TypographyTable map[rune]string
+
+var PlainTypography = TypographyTable{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`, '″': `"`,
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '−': "-",
+	'—': "--", '―': "--", '…': "...",
+	'\u00A0': " ", '\u2007': " ", '\u202F': " ", // non-breaking spaces
+	'\u2002': " ", '\u2003': " ", '\u2004': " ", '\u2005': " ", '\u2006': " ", '\u2008': " ", '\u2009': " ", '\u200A': " ",
+	'\u200B': "", '\u200C': "", '\u200D': "", '\u2060': "", '\uFEFF': "", // zero width
+}
+
+func NormalizeTypography(src string) string {
+	return eachJSONString(src, func(text string) string {
+		return escapeJSONText(replaceRunes(text, PlainTypography))
+	})
+}
+
+func NormalizeTypographyWith(table TypographyTable) CleanerFunc {
+	return func(src string) string {
+		return eachJSONString(src, func(text string) string {
+			return escapeJSONText(replaceRunes(text, table))
+		})
+	}
+}
+
+func SmartTypography(src string) string {
+	return eachJSONString(src, func(text string) string {
+		return smartTypography(text, true)
+	})
+}
+
+func NormalizeTextTypography(src string) string {
+	return replaceRunes(src, PlainTypography)
+}
+
+func SmartTextTypography(src string) string {
+	return smartTypography(src, false)
+}
+
+// inJSON: src is the contents of a JSON string, holding escapes, with \" being a quote
+func smartTypography(src string, inJSON bool) string {
+	var result strings.Builder
+	prev := rune(-1) // start of the text
+	for i := 0; i < len(src); {
+		r, n := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case inJSON && strings.HasPrefix(src[i:], `\"`):
+			r, n = '"', 2
+			fallthrough
+		case r == '\'' || r == '"':
+			opening := prev < 0 || unicode.IsSpace(prev) || strings.ContainsRune("([{<‘“—–-", prev)
+			switch {
+			case r == '\'' && opening:
+				r = '‘'
+			case r == '\'':
+				r = '’'
+			case opening:
+				r = '“'
+			default:
+				r = '”'
+			}
+		case inJSON && r == '\\' && i+1 < len(src):
+			result.WriteString(src[i : i+2]) // other escapes are kept, \n and \t as white space
+			if prev = rune(src[i+1]); strings.ContainsRune("nrt", prev) {
+				prev = ' '
+			}
+			i += 2
+			continue
+		case strings.HasPrefix(src[i:], "..."):
+			r, n = '…', 3
+		case strings.HasPrefix(src[i:], "--"):
+			r, n = '—', 2
+		}
+		result.WriteRune(r)
+		prev = r
+		i += n
+	}
+	return result.String()
+}
+
+// replaces the contents of each string value in src by the function's result, keys (strings
+// followed by a :) are left alone
+func eachJSONString(src string, f func(string) string) string {
+	var result strings.Builder
+	at := 0 // offset following the last text written
+	for i := 0; i < len(src); i++ {
+		if src[i] != '"' {
+			continue
+		}
+		j := skipQuoted(src, i)
+		if k := skipJSONSpace(src, j); k < len(src) && src[k] == ':' {
+			i = j - 1 // a key
+			continue
+		}
+		end := j
+		if end > i+1 && src[end-1] == '"' {
+			end -= 1
+		}
+		result.WriteString(src[at : i+1])
+		result.WriteString(f(src[i+1 : end]))
+		at, i = end, j-1
+	}
+	if at == 0 {
+		return src
+	}
+	result.WriteString(src[at:])
+	return result.String()
+}
+
+// escapes any " or \ in text that does not start an escape of the JSON string it is in
+func escapeJSONText(text string) string {
+	if !strings.ContainsAny(text, `"\`) {
+		return text
+	}
+	var result strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(`"\\/bfnrtu`, text[i+1]) >= 0:
+			result.WriteString(text[i : i+2])
+			i += 1
+		case c == '\\' || c == '"':
+			result.WriteByte('\\')
+			result.WriteByte(c)
+		default:
+			result.WriteByte(c)
+		}
+	}
+	return result.String()
+}
+
+func replaceRunes(src string, table TypographyTable) string {
+	i := strings.IndexFunc(src, func(r rune) bool {
+		_, ok := table[r]
+		return ok
+	})
+	if i < 0 {
+		return src
+	}
+	var result strings.Builder
+	result.WriteString(src[:i])
+	for _, r := range src[i:] {
+		if s, ok := table[r]; ok {
+			result.WriteString(s)
+		} else {
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
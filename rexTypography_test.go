@@ -0,0 +1,102 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+// uses the wordtext fixture of rexGather_test.go, with its curly quotes
+func TestTypography(t *testing.T) {
+	expected := `apple   banana   cherry
+date fig grape
+'apple' 'banana' 'cherry'
+Apple Banana Cherry
+"date" "fig" "grape"
+Date Fig Grape
+  berry  mellon  orange 
+  (berry), 'mellon';  "orange".
+`
+	text := NormalizeTextTypography(wordtext)
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// and back again, the curly quotes can be worked out from where the quotes are
+	expected = strings.Replace(strings.Replace(wordtext, `'mellon'`, sngSQt+"mellon"+sngEQt, 1), `"orange"`, dblSQt+"orange"+dblEQt, 1)
+	if text = SmartTextTypography(text); text != expected {
+		tst.Failed(t, dbg.IAm()+" Smart", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Smart")
+	}
+
+	expected = "It’s — well… a “dash” 1–2"
+	if text = SmartTextTypography("It's -- well... a \"dash\" 1–2"); text != expected {
+		tst.Failed(t, dbg.IAm()+" Dashes", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Dashes")
+	}
+
+	// a table of its own, composed with RexCleanup
+	table := TypographyTable{'–': "--", '\u00A0': " ", '\u200B': ""}
+	expected = `"pages": "10--12 ok"`
+	text = RexCleanup("\"pages\": \"10–12\u00A0o\u200Bk\"", regexp.MustCompile(`((?s).*?": )(".*?")((?s).*)`), NormalizeTypographyWith(table))
+	if text != expected {
+		tst.Failed(t, dbg.IAm()+" Table", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Table")
+	}
+}
+
+var typographySource = `{
+  "title": "He said “hi” – then… left",
+  "quote": "It's a \"quoted\" word -- \\ ok...",
+  "list": [ "‘single’", "plain" ],
+  "“key”": "don’t"
+}`
+
+// the cleaners only change the string values, leaving valid JSON
+func TestTypographyJSON(t *testing.T) {
+	objectRex := regexp.MustCompile(`((?s).*?{)((?s).*)(}(?s).*)`)
+	for _, c := range []struct {
+		name     string
+		cf       CleanerFunc
+		expected string
+	}{
+		{"Normalize", NormalizeTypography, `{
+  "title": "He said \"hi\" - then... left",
+  "quote": "It's a \"quoted\" word -- \\ ok...",
+  "list": [ "'single'", "plain" ],
+  "“key”": "don't"
+}`},
+		{"Smart", SmartTypography, `{
+  "title": "He said “hi” – then… left",
+  "quote": "It’s a “quoted” word — \\ ok…",
+  "list": [ "‘single’", "plain" ],
+  "“key”": "don’t"
+}`},
+	} {
+		text := RexJSONCleanup(typographySource, objectRex, c.cf)
+		if err := VerifyEquivalent([]byte(c.expected), []byte(text)); err != nil || text != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.name, fmt.Sprint("Expected in green, genereted in red: ", err))
+			tst.AsGreen(c.expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package rex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+/*
+	Checking that a cleanup only changed the layout of the JSON, not the data.
+
+	VerifyEquivalent:
+		Decodes both texts and returns an EquivalenceError for the first place the data
+		differs, or an error if either text is not JSON.  Objects are compared by their
+		members, so the order of members does not matter, and numbers by their exact value.  The
+		texts may hold several JSON values, as from a json.Encoder.
+
+	EquivalenceError: TYPE
+		Reports where the data differs, Path is a JSONPath as used by RexJSONCleanupPath
+
+	RexJSONCleanupSafe:
+		RexJSONCleanup that returns an error rather than output that differs from the source
+	RexJSONCleanupPostSafe:
+		RexJSONCleanupPost that returns an error rather than output that differs from the source
+*/
+
+type EquivalenceError struct {
+	Path   string
+	Reason string
+}
+
+func (e *EquivalenceError) Error() string {
+	return fmt.Sprintf("rex: JSON differs at %s: %s", e.Path, e.Reason)
+}
+
+func VerifyEquivalent(before, after []byte) error {
+	a, err := decodeAll(before)
+	if err != nil {
+		return fmt.Errorf("rex: before is not valid JSON: %v", err)
+	}
+	b, err := decodeAll(after)
+	if err != nil {
+		return fmt.Errorf("rex: after is not valid JSON: %v", err)
+	}
+	if len(a) != len(b) {
+		return &EquivalenceError{"$", fmt.Sprintf("%d values before, %d after", len(a), len(b))}
+	}
+	for i := range a {
+		path := "$"
+		if len(a) > 1 {
+			path = fmt.Sprintf("$[%d]", i) // each of several values
+		}
+		if err := equivalent(path, a[i], b[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RexJSONCleanupSafe(src string, rx *regexp.Regexp, cf CleanerFunc) (string, error) {
+	return RexJSONCleanupPostSafe(src, rx, cf, nil)
+}
+
+func RexJSONCleanupPostSafe(src string, rx *regexp.Regexp, cf CleanerFunc, post CleanerFunc) (string, error) {
+	result := RexJSONCleanupPost(src, rx, cf, post)
+	if err := VerifyEquivalent([]byte(src), []byte(result)); err != nil {
+		return src, err
+	}
+	return result, nil
+}
+
+func decodeAll(src []byte) ([]interface{}, error) {
+	var values []interface{}
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			return values, nil
+		} else if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
+
+func equivalent(path string, a, b interface{}) error {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			return &EquivalenceError{path, "object became " + jsonKind(b)}
+		}
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := b[k]; !ok {
+				return &EquivalenceError{memberPath(path, k), "member removed"}
+			}
+			if err := equivalent(memberPath(path, k), a[k], b[k]); err != nil {
+				return err
+			}
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				return &EquivalenceError{memberPath(path, k), "member added"}
+			}
+		}
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			return &EquivalenceError{path, "array became " + jsonKind(b)}
+		}
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if err := equivalent(fmt.Sprintf("%s[%d]", path, i), a[i], b[i]); err != nil {
+				return err
+			}
+		}
+		if len(a) != len(b) {
+			return &EquivalenceError{path, fmt.Sprintf("%d entries before, %d after", len(a), len(b))}
+		}
+	case json.Number:
+		n, ok := b.(json.Number)
+		if ok && n != a {
+			x, okA := new(big.Rat).SetString(string(a))
+			y, okB := new(big.Rat).SetString(string(n))
+			ok = okA && okB && x.Cmp(y) == 0 // exact, 7 is 7.0 but large integers are not rounded
+		}
+		if !ok {
+			return &EquivalenceError{path, fmt.Sprintf("%v became %s", a, jsonText(b))}
+		}
+	default: // string, bool or nil
+		if a != b {
+			return &EquivalenceError{path, fmt.Sprintf("%s became %s", jsonText(a), jsonText(b))}
+		}
+	}
+	return nil
+}
+
+var pathNameRex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func memberPath(path, key string) string {
+	if pathNameRex.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+	return jsonText(v)
+}
+
+func jsonText(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
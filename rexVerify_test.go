@@ -0,0 +1,79 @@
+package rex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+func TestVerifyEquivalent(t *testing.T) {
+	tracksRex := regexp.MustCompile(`((?sm).*?^  "tracks": \[\n)((?s).*?)((?sm)^  \].*)`)
+	text, err := RexJSONCleanupSafe(complexSource, tracksRex, cleanTrack)
+	if err != nil || text == complexSource {
+		tst.Failed(t, dbg.IAm(), fmt.Sprint("Cleanup failed: ", err))
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	for _, c := range []struct{ name, after, expected string }{
+		{"Member", strings.Replace(complexSource, `"trk-len": "8:23.800",`, "", 1),
+			`rex: JSON differs at $.tracks[4].trk-len: member removed`},
+		{"Value", strings.Replace(complexSource, `"end": "22:16'4!42"`, `"end": "22:16'4!4"`, 1),
+			`rex: JSON differs at $.tracks[4].silences[1].end: "22:16'4!42" became "22:16'4!4"`},
+		{"Entry", strings.Replace(complexSource, `"number": 7,`, `"number": 7, "7": 7,`, 1),
+			`rex: JSON differs at $.tracks[6]["7"]: member added`},
+		{"Number", strings.Replace(complexSource, `"trackCount": 7`, `"trackCount": 7.0`, 1), ``},
+		{"Comma", strings.Replace(complexSource, `"number": 7,`, `"number": 7`, 1),
+			`rex: after is not valid JSON: invalid character '"' after object key:value pair`},
+	} {
+		err := VerifyEquivalent([]byte(complexSource), []byte(c.after))
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.name, "Expected in green, genereted in red")
+			tst.AsGreen(c.expected)
+			tst.AsRed(msg)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.name)
+		}
+	}
+
+	// integers above 2^53 are the same float64, but not the same number
+	for _, c := range []struct{ name, before, after, expected string }{
+		{"BigInt", `{"id": 12345678901234567891}`, `{"id": 12345678901234567890}`,
+			`rex: JSON differs at $.id: 12345678901234567891 became 12345678901234567890`},
+		{"BigIntSame", `{"id": 12345678901234567891}`, `{ "id": 12345678901234567891 }`, ``},
+		{"Exponent", `[1e3, 0.5]`, `[1000, 5E-1]`, ``},
+	} {
+		err := VerifyEquivalent([]byte(c.before), []byte(c.after))
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != c.expected {
+			tst.Failed(t, dbg.IAm()+" "+c.name, "Expected in green, genereted in red")
+			tst.AsGreen(c.expected)
+			tst.AsRed(msg)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" "+c.name)
+		}
+	}
+
+	// a cleaner that loses the last track
+	dropLast := func(src string) string {
+		return src[:strings.LastIndex(src, "},")+1] + "\n"
+	}
+	text, err = RexJSONCleanupSafe(complexSource, tracksRex, dropLast)
+	if err == nil || text != complexSource {
+		tst.Failed(t, dbg.IAm()+" Safe", "Corrupted output not caught")
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Safe")
+	}
+}
@@ -0,0 +1,168 @@
+package rex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	Whitespace cleanup that leaves quoted strings alone.
+
+	A string starts at a " and ends at the next " not escaped by a \, or at the end of the
+	line, as a JSON string cannot hold a newline.  Spaces and tabs inside a string are never
+	changed, so these are safe on JSON text and on the bodies handed to a CleanerFunc.
+
+	TrimTrailing: CleanerFunc
+		Removes the spaces and tabs ending each line and ending the text
+	CollapseBlankLines:
+		Returns a CleanerFunc reducing each run of blank lines (lines between two newlines
+		holding only spaces and tabs) to at most keep lines, 0 to remove them all.  The line
+		before the first newline is never blank, so a body's leading "\n" is kept.
+	CollapseInnerSpaces: CleanerFunc
+		Replaces each run of spaces and tabs within a line with a single space, leaving the
+		indentation and any trailing white space as they are -- this also removes the padding
+		added by AlignValues
+	TabsToSpaces:
+		Returns a CleanerFunc replacing each tab with the spaces up to the next tab stop, a
+		stop every width columns (8 if width < 1)
+	NormalizeNewlines: CleanerFunc
+		Replaces "\r\n" and any lone "\r" with "\n"
+*/
+
+func TrimTrailing(src string) string {
+	var result strings.Builder
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == '"':
+			j := skipQuoted(src, i)
+			result.WriteString(src[i:j])
+			i = j
+		case c == ' ' || c == '\t':
+			j := skipBlanks(src, i)
+			if j < len(src) && src[j] != '\n' && !strings.HasPrefix(src[j:], "\r\n") {
+				result.WriteString(src[i:j])
+			}
+			i = j
+		default:
+			result.WriteByte(c)
+			i += 1
+		}
+	}
+	return result.String()
+}
+
+func CollapseBlankLines(keep int) CleanerFunc {
+	return func(src string) string {
+		lines := strings.Split(src, "\n")
+		result := []string{lines[0]}
+		blanks := 0
+		for i := 1; i < len(lines); i++ {
+			if i < len(lines)-1 && strings.Trim(lines[i], " \t\r") == "" {
+				if blanks += 1; blanks > keep {
+					continue
+				}
+			} else {
+				blanks = 0
+			}
+			result = append(result, lines[i])
+		}
+		return strings.Join(result, "\n")
+	}
+}
+
+func CollapseInnerSpaces(src string) string {
+	var result strings.Builder
+	lineStart := true // only white space so far on the line
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == '"':
+			j := skipQuoted(src, i)
+			result.WriteString(src[i:j])
+			i, lineStart = j, false
+		case c == ' ' || c == '\t':
+			j := skipBlanks(src, i)
+			if lineStart || j == len(src) || src[j] == '\n' || src[j] == '\r' {
+				result.WriteString(src[i:j])
+			} else {
+				result.WriteByte(' ')
+			}
+			i = j
+		default:
+			result.WriteByte(c)
+			i, lineStart = i+1, c == '\n'
+		}
+	}
+	return result.String()
+}
+
+func TabsToSpaces(width int) CleanerFunc {
+	if width < 1 {
+		width = 8
+	}
+	return func(src string) string {
+		if !strings.Contains(src, "\t") {
+			return src
+		}
+		var result strings.Builder
+		col := 0
+		for i := 0; i < len(src); {
+			switch c := src[i]; c {
+			case '"':
+				j := skipQuoted(src, i)
+				result.WriteString(src[i:j])
+				col += utf8.RuneCountInString(src[i:j])
+				i = j
+			case '\t':
+				n := width - col%width
+				result.WriteString(strings.Repeat(" ", n))
+				col += n
+				i += 1
+			case '\n':
+				result.WriteByte(c)
+				col = 0
+				i += 1
+			default:
+				_, n := utf8.DecodeRuneInString(src[i:])
+				result.WriteString(src[i : i+n])
+				col += 1
+				i += n
+			}
+		}
+		return result.String()
+	}
+}
+
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+func NormalizeNewlines(src string) string {
+	if !strings.Contains(src, "\r") {
+		return src
+	}
+	return newlineReplacer.Replace(src)
+}
+
+// offset following the string starting with the quote at src[i], or of the newline (or end
+// of src) ending an unterminated string
+func skipQuoted(src string, i int) int {
+	for i += 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) && src[i+1] != '\n' {
+				i += 1
+			}
+		case '"':
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// offset of the first byte following the spaces and tabs at src[i]
+func skipBlanks(src string, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i += 1
+	}
+	return i
+}
@@ -0,0 +1,56 @@
+package rex
+
+import (
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+var spacedSource = "{  \r\n" +
+	"  \"title\":    \"Merry  Foo \",  \r\n" +
+	"\r\n" +
+	"  \t\r\n" +
+	"  \"note\":\t\"a\tb  \\\"  c  \",\t\r\n" +
+	"\r\n" +
+	"  \"list\": [  1,   2,\t3  ]\r\n" +
+	"}  "
+
+func TestWhitespace(t *testing.T) {
+	expected := "{\n" +
+		"  \"title\": \"Merry  Foo \",\n" +
+		"\n" +
+		"  \"note\": \"a\tb  \\\"  c  \",\n" +
+		"\n" +
+		"  \"list\": [ 1, 2, 3 ]\n" +
+		"}"
+	text := TrimTrailing(CollapseInnerSpaces(NormalizeNewlines(spacedSource)))
+	text = CollapseBlankLines(1)(text)
+	if text != expected {
+		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm())
+	}
+
+	// a body keeps its leading newline, the blank line before the closer is removed
+	expected = "\n  \"a\": 1,\n  \"b\": 2\n"
+	if text = CollapseBlankLines(0)("\n  \"a\": 1,\n\n  \n  \"b\": 2\n  \n"); text != expected {
+		tst.Failed(t, dbg.IAm()+" Body", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Body")
+	}
+
+	// tabs inside the strings are kept, the stops count the runes of the strings
+	expected = "{\n    \"é\":    \"a\tb\",\n        \"c\":    1\n}"
+	if text = TabsToSpaces(4)("{\n\t\"é\":\t\"a\tb\",\n\t\t\"c\":\t1\n}"); text != expected {
+		tst.Failed(t, dbg.IAm()+" Tabs", "Expected in green, genereted in red")
+		tst.AsGreen(expected)
+		tst.AsRed(text)
+	} else {
+		tst.Passed(t, "", dbg.IAm()+" Tabs")
+	}
+}
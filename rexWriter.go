@@ -0,0 +1,130 @@
+package rex
+
+import (
+	"io"
+)
+
+/*
+	Streaming cleanup of JSON.
+
+	Writer: TYPE
+		An io.Writer that applies Rules to the JSON written to it (e.g. by a json.Encoder)
+		and passes the result on to another io.Writer.  The JSON is only held until each
+		top level value is complete, which is then cleaned and written out, giving the same
+		output as ApplyRules on the value's text.
+
+	NewWriter:
+		Creates a Writer that writes the cleaned JSON to w
+
+	Writer.SetFlushElements:
+		When set and a top level value is an array spread over several lines, each entry of
+		the array is cleaned and written out as soon as it is complete.  The rules are
+		applied to the opening line, the lines of each entry (keeping their indentation and
+		trailing ',') and the closing line on their own, so they must not rely on the rest
+		of the array, e.g. use SelectNamed rather than selectors limited with SelectDepth.
+
+	Writer.Flush:
+		Cleans and writes out any incomplete JSON still held
+
+	Writer.Close:
+		Flushes the Writer, and closes w if it is an io.Closer
+*/
+
+type Writer struct {
+	w        io.Writer
+	rules    []Rule
+	elements bool
+
+	buf     []byte // text held until the value / entry is complete
+	scanned int    // buf has been scanned up to here
+	depth   int
+	inStr   bool
+	escaped bool
+	inArray bool // in a top level array whose entries are written as they complete
+	content bool // buf holds something other than white space
+}
+
+func NewWriter(w io.Writer, rules ...Rule) *Writer {
+	return &Writer{w: w, rules: rules}
+}
+
+func (w *Writer) SetFlushElements(on bool) {
+	w.elements = on
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for ; w.scanned < len(w.buf); w.scanned++ {
+		c := w.buf[w.scanned]
+		if w.inStr {
+			switch {
+			case w.escaped:
+				w.escaped = false
+			case c == '\\':
+				w.escaped = true
+			case c == '"':
+				w.inStr = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			w.inStr, w.content = true, true
+		case '{', '[':
+			if w.depth == 0 && c == '[' && w.elements {
+				w.inArray = true
+			}
+			w.depth += 1
+			w.content = true
+		case '}', ']':
+			w.depth -= 1
+			w.content = true
+		case '\n':
+			if err := w.lineEnd(); err != nil {
+				return 0, err
+			}
+		case ' ', '\t', '\r':
+		default:
+			w.content = true
+		}
+	}
+	return len(p), nil
+}
+
+// writes out any completed value or array entry, at the end of each line
+func (w *Writer) lineEnd() error {
+	if !w.content || w.depth > 1 || (w.depth == 1 && !w.inArray) {
+		return nil
+	}
+	w.inArray = w.inArray && w.depth == 1
+	return w.emit()
+}
+
+// cleans and writes out buf up to the end of the current line
+func (w *Writer) emit() error {
+	text := ApplyRules(string(w.buf[:w.scanned+1]), w.rules...)
+	w.buf = w.buf[w.scanned+1:]
+	w.scanned, w.content = -1, false // Write's loop moves on to buf[0]
+	_, err := io.WriteString(w.w, text)
+	return err
+}
+
+func (w *Writer) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	text := ApplyRules(string(w.buf), w.rules...)
+	w.buf, w.scanned, w.content = w.buf[:0], 0, false
+	_, err := io.WriteString(w.w, text)
+	return err
+}
+
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package rex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jayacarlson/dbg"
+	"github.com/jayacarlson/tst"
+)
+
+// counts the writes reaching the underlying writer
+type countingWriter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.writes += 1
+	return c.buf.Write(p)
+}
+
+func TestWriterMatchesStrings(t *testing.T) {
+	rules := []Rule{
+		{Sel: SelectNamed('{'), Cleaner: PackLines},
+		{Rex: regexp.MustCompile(`((?s).*? +"numbers": \[)\n((?s).*?)((?s)\n +\].*)`), Cleaner: PackLines},
+	}
+	obj := simpleObject{Name: "Test {", Numbers: []int{1, 2, 3}}
+	list := []simpleObject{obj, obj, obj}
+
+	for _, elements := range []bool{false, true} {
+		var expected string
+		var out countingWriter
+		w := NewWriter(&out, rules...)
+		w.SetFlushElements(elements)
+		// with FlushElements: obj, the list's opening line, each entry and its closing line
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		for _, v := range []interface{}{obj, list} {
+			b, _ := json.MarshalIndent(v, "", "  ")
+			expected += ApplyRules(string(b)+"\n", rules...)
+			enc.Encode(v)
+		}
+		w.Close()
+
+		name := dbg.IAm()
+		if elements {
+			name += " FlushElements"
+		}
+		text := out.buf.String()
+		if text != expected {
+			tst.Failed(t, name, "Expected in green, genereted in red")
+			tst.AsGreen(expected)
+			tst.AsRed(text)
+		} else if writes := 3 + len(list); !elements && out.writes != 2 || elements && out.writes != writes {
+			tst.Failed(t, name, fmt.Sprintf("Unexpected number of writes: %d", out.writes))
+		} else {
+			tst.Passed(t, "", name)
+		}
+	}
+}
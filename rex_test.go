@@ -345,22 +345,6 @@ func TestRexJSONJustPackLines(t *testing.T) {
 	}
 }
 
-// json.Marshal outputs any empty object or array as {} & [] -- this routine will
-// convert those to the multi-line versions the rex functions need; which will later
-// convert them back...
-func fixEmptyObjectsAndArrays(src string) string {
-	namedObjectRex := regexp.MustCompile(`((?sm).*?^)( *)("\w+?": {)((?s)}.*)`)
-	// test only uses empty named objects (for now)
-	//	namedArrayRex := regexp.MustCompile(`((?sm).*?^)( *)("\w+?": \[)((?s)\].*)`)
-	//	unnamedObjectRex := regexp.MustCompile(`((?sm).*?^)( *)({)((?s)}.*)`)
-	//	unnamedArrayRex := regexp.MustCompile(`((?sm).*?^)( *)(\[)((?s)\].*)`)
-
-	src = RexReplace(src, namedObjectRex, func(x []string, rx *regexp.Regexp, rf RexFunc) string {
-		return x[1] + x[2] + x[3] + "\n" + x[2] + RexReplace(x[4], rx, rf)
-	})
-	return src
-}
-
 func padPackMaxSubObjects(src string) string {
 	return "\n" + RexJSONCleanup(src, NamedJSONObjectRex, func(s string) string { return PackLinesMax(s, 35) })
 }
@@ -489,11 +473,12 @@ func TestRexJSONObjectsOfObjects(t *testing.T) {
 	objOfObjRex := regexp.MustCompile(`((?s).*?\n  "objectOfObjects": {)\n((?s).*?)((?s)\n  }.*)`)
 	objOfSubObjRex := regexp.MustCompile(`((?s).*?\n  "objectOfSubObjects": {)\n((?s).*?)((?s)\n  }.*)`)
 
-	source = fixEmptyObjectsAndArrays(source)
+	// the regexps need the multi-line versions of {}, packing converts them back
+	source = NormalizeEmpties(EmptyOptions{Open: true})(source)
 
 	text := RexJSONCleanup(source, objOfObjRex, padPackMaxSubObjects)
 	text = RexJSONCleanup(text, objOfSubObjRex, padPackObjsDepth2)
-	text = removeTrailingSpaces(text)
+	text = TrimTrailing(text)
 	if text != expected {
 		tst.Failed(t, dbg.IAm(), "Expected in green, genereted in red")
 		tst.AsGreen(expected)
@@ -530,14 +515,34 @@ func TestRexJSONObjectsOfObjects(t *testing.T) {
     }
   }
 }`
-	text = RexJSONCleanup(source, UnnamedJSONObjectRex, packEverything)
-	text = removeBlankLines(text)
-	text = removeTrailingSpaces(text)
+	text = AutoPack(source, AutoPackOptions{})
 	if text != packAllExpect {
-		tst.Failed(t, dbg.IAm()+" Using packEverything", "Expected in green, genereted in red")
+		tst.Failed(t, dbg.IAm()+" Using AutoPack", "Expected in green, genereted in red")
 		tst.AsGreen(packAllExpect)
 		tst.AsRed(text)
 	} else {
-		tst.Passed(t, "", dbg.IAm()+" Using packEverything")
+		tst.Passed(t, "", dbg.IAm()+" Using AutoPack")
+	}
+}
+
+func TestTailRexes(t *testing.T) {
+	// leftmost-longest regexps match as they would without the rewrite
+	for _, c := range []struct{ rex, src string }{
+		{`(a|ab)(.*?)((?s).*)`, "abc"},
+		{`(a*)(a*)((?s).*)`, "aaab"},
+		{`(x|xy|xyz)(y*)((?s).*)`, "xyzyy"},
+		{`((?s).*?)(b+|bb)((?s).*)`, "aabbbc"},
+	} {
+		rx := regexp.MustCompile(c.rex)
+		rx.Longest()
+		tr := newTailRex(rx)
+		expected := fmt.Sprint(rx.FindStringSubmatchIndex(c.src))
+		if text := fmt.Sprint(findSubmatchIndex(tr, c.src)); text != expected || tr.head == nil {
+			tst.Failed(t, dbg.IAm()+" Longest "+c.rex, "Expected in green, genereted in red")
+			tst.AsGreen(expected)
+			tst.AsRed(text)
+		} else {
+			tst.Passed(t, "", dbg.IAm()+" Longest "+c.rex)
+		}
 	}
 }